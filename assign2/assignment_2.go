@@ -1,70 +1,420 @@
 package main
 
 import (
-	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 )
 
-func main() {
+// config holds the options that change how a log is read.
+type config struct {
+	Parser LineParser  // nil to detect the layout from the first lines
+	Levels *levelTable // canonical levels and their aliases
+
+	Since, Until time.Time     // when either is set, only timestamped lines in [Since, Until) are counted
+	Bucket       time.Duration // width of the histogram buckets, zero for no histogram
+
+	Workers int      // parser goroutines; one or fewer reads line by line
+	Top     int      // signatures reported per level
+	Rules   *ruleSet // alert rules, nil for none
+
+	Filter  *filter       // lines to count, nil for all of them
+	Print   *matchPrinter // prints the counted lines, nil to only count them
+	Entries *entryRules   // how lines join into entries, nil for one entry per line
+}
+
+// inWindow reports whether a line at t falls within the --since and --until window.
+func (c config) inWindow(t time.Time) bool {
+	if c.Since.IsZero() && c.Until.IsZero() {
+		return true
+	}
+	if t.IsZero() {
+		return false
+	}
+	return (c.Since.IsZero() || !t.Before(c.Since)) && (c.Until.IsZero() || t.Before(c.Until))
+}
+
+// analysis is the outcome of reading one log.
+type analysis struct {
+	Format string         // name of the layout the log was read with
+	Lines  int            // entries counted, blank lines included; an entry is a line unless --multiline joins them
+	Levels map[string]int // entries per canonical level, with the rest under unclassified
+	Read   int            // physical lines read, counted or not
+
+	OutsideWindow int          // entries left out by --since and --until
+	FilteredOut   int          // entries left out by the filter
+	Histogram     *histogram   // nil unless a bucket width was given
+	Signatures    signatureSet // normalized messages by level
+	Recent        *timeline    // nil unless a rule has a window
+}
+
+// counter tallies lines as they arrive. Without a configured parser it holds back the
+// first lines until it has enough of them to detect the layout, or until flush is called.
+// With entry rules, it holds back the lines of an entry until the next one starts.
+type counter struct {
+	cfg     config
+	parser  LineParser
+	pending []string // lines held back for detection
+	sampled int      // non-empty lines among pending
+	entry   []string // lines of the entry being assembled
+	result  analysis
+}
 
-	if len(os.Args) < 2 {
-		fmt.Println("Usage: go run main.go <log_file>")
+func newCounter(cfg config) *counter {
+	c := &counter{cfg: cfg, parser: cfg.Parser, result: analysis{Levels: map[string]int{}, Signatures: signatureSet{}}}
+	if cfg.Bucket > 0 {
+		c.result.Histogram = newHistogram(cfg.Bucket)
+	}
+	c.result.Recent = cfg.Rules.newTimeline()
+	return c
+}
+
+// add counts one line, or holds it back while the layout is still being detected.
+func (c *counter) add(line string) {
+	if c.parser == nil {
+		c.pending = append(c.pending, line)
+		if strings.TrimSpace(line) != "" {
+			c.sampled++
+		}
+		if c.sampled >= detectSampleLines {
+			c.flush()
+		}
+		return
+	}
+
+	if c.cfg.Entries == nil {
+		c.addEntry(line, nil)
+		return
+	}
+	if len(c.entry) > 0 && len(c.entry) < c.cfg.Entries.maxLines && c.cfg.Entries.continues(line, c.parser) {
+		c.entry = append(c.entry, line)
 		return
 	}
+	c.endEntry()
+	c.entry = []string{line}
+}
 
-	logFilePath := os.Args[1]
+// endEntry counts the entry being assembled, if there is one.
+func (c *counter) endEntry() {
+	if len(c.entry) > 0 {
+		entry := c.entry
+		c.entry = nil
+		c.addEntry(entry[0], entry[1:])
+	}
+}
 
-	file, err := os.Open(logFilePath)
+// addEntry counts an entry that starts with first and goes on with the rest. The level,
+// time and signature come from the first line, and the filter sees every line of it.
+func (c *counter) addEntry(first string, rest []string) {
+	at := position{Line: c.result.Read + 1}
+	c.result.Read += 1 + len(rest)
 
-	if err != nil {
-		fmt.Printf("Error while opening file %s: %v\n", logFilePath, err)
+	e, ok := c.parser.Parse(first)
+	level, message := unclassified, first
+	if ok {
+		level, message = c.cfg.Levels.classify(e.Level), e.Message
+	}
+
+	text := message
+	if len(rest) > 0 {
+		text += "\n" + strings.Join(rest, "\n")
+	}
+
+	counted := false
+	switch {
+	case !c.cfg.inWindow(e.Time):
+		c.result.OutsideWindow++
+	case c.cfg.Filter != nil && !c.cfg.Filter.keep(e, level, text, c.cfg.Levels):
+		c.result.FilteredOut++
+	default:
+		counted = true
+		c.count(e, level, message, at)
+	}
+	if c.cfg.Print != nil {
+		c.cfg.Print.add(first, counted)
+		for _, line := range rest {
+			c.cfg.Print.add(line, counted)
+		}
+	}
+}
+
+// count tallies an entry that passed the time window and the filter.
+func (c *counter) count(e Entry, level, message string, at position) {
+	c.result.Lines++
+	c.result.Levels[level]++
+	text := c.result.Signatures.add(level, message, at)
+	if e.Time.IsZero() {
+		return
+	}
+	if c.result.Histogram != nil {
+		c.result.Histogram.add(e.Time, level)
+	}
+	if c.result.Recent != nil {
+		c.result.Recent.add(e.Time, level, text)
+	}
+}
+
+// flush settles the layout with the lines seen so far and counts the lines held back.
+func (c *counter) flush() {
+	if c.parser != nil {
 		return
 	}
-	defer func() {
-		if closeErr := file.Close(); closeErr != nil {
-			fmt.Printf("Error closing file %s: %v\n", logFilePath, closeErr)
+
+	var sample []string
+	for _, line := range c.pending {
+		if strings.TrimSpace(line) != "" {
+			sample = append(sample, line)
+		}
+	}
+	c.parser = detectParser(sample)
+
+	pending := c.pending
+	c.pending = nil
+	for _, line := range pending {
+		c.add(line)
+	}
+}
+
+// close counts whatever is still held back, at the end of the input.
+func (c *counter) close() {
+	c.flush()
+	c.endEntry()
+}
+
+// analysis returns the counts so far, settling the layout first if need be.
+// An entry still being assembled is not counted until close is called.
+func (c *counter) analysis() analysis {
+	c.flush()
+	c.result.Format = c.parser.Name()
+	return c.result
+}
+
+// analyze reads every entry of r and counts it under its canonical level. Printing matches
+// and joining lines into entries read line by line, as neither can start halfway through a log.
+func analyze(r io.Reader, cfg config) (analysis, error) {
+	if cfg.Workers > 1 && cfg.Print == nil && cfg.Entries == nil {
+		return analyzeParallel(r, cfg, cfg.Workers, pipelineChunkSize)
+	}
+
+	// Lines are split by the same chunk reader the workers use, so that no line is too long
+	// for one path and not the other.
+	c := newCounter(cfg)
+	reader := &chunkReader{r: r, size: pipelineChunkSize}
+	for {
+		chunk, err := reader.next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			c.close()
+			return c.analysis(), err
+		}
+		eachLine(chunk, c.add)
+	}
+	c.close()
+	return c.analysis(), nil
+}
+
+func main() {
+
+	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
+	format := flags.String("format", "auto", "log layout: auto, bracket, logfmt, json, syslog or combined")
+	levelsFile := flags.String("levels", "", "JSON file with the level table, least severe level first")
+	bucket := flags.String("histogram", "", "chart counts per minute, hour or day")
+	since := flags.String("since", "", "count only lines at or after this time, date or duration ago")
+	until := flags.String("until", "", "count only lines before this time, date or duration ago")
+	followFile := flags.Bool("follow", false, "keep reading the file as it grows, through truncation and rotation")
+	refresh := flags.Duration("refresh", 5*time.Second, "how often --follow prints the running counts")
+	workers := flags.Int("workers", runtime.GOMAXPROCS(0), "parser goroutines for large files")
+	top := flags.Int("top", 5, "message signatures to report per level")
+	output := flags.String("output", "text", "report format: text, json, csv, markdown or prometheus")
+	rulesFile := flags.String("rules", "", "YAML or JSON file of alert rules; exit with status 1 when one is violated")
+	var include, exclude, fields stringList
+	flags.Var(&include, "include", "count only lines whose message matches this regular expression; repeatable")
+	flags.Var(&exclude, "exclude", "leave out lines whose message matches this regular expression; repeatable")
+	flags.Var(&fields, "field", "count only lines with key=value, or without it for key!=value; repeatable")
+	minLevel := flags.String("min-level", "", "count only lines at or above this level")
+	maxLevel := flags.String("max-level", "", "count only lines at or below this level")
+	printMatches := flags.Bool("print-matches", false, "print the counted lines instead of the report, like grep -n")
+	contextLines := flags.Int("context", 0, "with --print-matches, also print this many lines around each match")
+	multiline := flags.String("multiline", "off", "join lines into entries: off, start (lines that do not start an entry continue the one before) or indent")
+	var entryStarts stringList
+	flags.Var(&entryStarts, "entry-start", "regular expression for lines that start an entry, instead of lines the layout reads; repeatable")
+	maxEntryLines := flags.Int("max-entry-lines", 1000, "longest entry --multiline makes, in lines")
+	compareFile := flags.String("compare", "", "report saved earlier with --output json to compare this run with")
+	maxRegression := flags.String("max-regression", "", "with --compare, exit with status 1 when an error level or signature grows by more than this percentage")
+	flags.Usage = func() {
+		fmt.Println("Usage: go run . [--format auto|bracket|logfmt|json|syslog|combined] [--levels levels.json]")
+		fmt.Println("                [--histogram minute|hour|day] [--since TIME] [--until TIME]")
+		fmt.Println("                [--follow [--refresh 5s]] [--workers N] [--top N]")
+		fmt.Println("                [--output text|json|csv|markdown|prometheus] [--rules rules.yaml]")
+		fmt.Println("                [--include REGEX] [--exclude REGEX] [--field key=value] [--min-level L] [--max-level L]")
+		fmt.Println("                [--print-matches [--context N]] [--multiline off|start|indent] [--entry-start REGEX]")
+		fmt.Println("                [--max-entry-lines N] [--compare baseline.json [--max-regression PCT]]")
+		fmt.Println("                <log_file|glob|-> ...")
+	}
+	_ = flags.Parse(os.Args[1:])
+
+	if flags.NArg() < 1 {
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	render, ok := reportWriters[*output]
+	if !ok {
+		fail(fmt.Sprintf("unknown output format %q, use %s", *output, outputNames()))
+	}
+
+	parser, err := lookupParser(*format)
+	if err != nil {
+		fail(err)
+	}
+
+	levels := defaultLevelTable()
+	if *levelsFile != "" {
+		levels, err = loadLevelTable(*levelsFile)
+		if err != nil {
+			fail(err)
+		}
+	}
+
+	cfg := config{Parser: parser, Levels: levels, Workers: *workers, Top: *top}
+	if *rulesFile != "" {
+		if cfg.Rules, err = loadRules(*rulesFile, levels); err != nil {
+			fail(err)
+		}
+	}
+	if cfg.Filter, err = newFilter(include, exclude, fields, *minLevel, *maxLevel, levels); err != nil {
+		fail(err)
+	}
+	if *printMatches {
+		cfg.Print = newMatchPrinter(os.Stdout, *contextLines)
+	}
+	if cfg.Entries, err = newEntryRules(*multiline, entryStarts, *maxEntryLines); err != nil {
+		fail(err)
+	}
+
+	var baseline *report
+	if *compareFile != "" {
+		if *followFile || *printMatches {
+			fail("--compare needs a report, so it does not work with --follow or --print-matches")
+		}
+		b, err := loadBaseline(*compareFile)
+		if err != nil {
+			fail(err)
+		}
+		baseline = &b
+	}
+	regressionThreshold := -1.0
+	if *maxRegression != "" {
+		if regressionThreshold, err = strconv.ParseFloat(strings.TrimSuffix(*maxRegression, "%"), 64); err != nil || regressionThreshold < 0 || baseline == nil {
+			fail("--max-regression takes a percentage such as 10, and needs --compare")
 		}
-	}()
+	}
+	if *bucket != "" {
+		width, ok := bucketWidths[*bucket]
+		if !ok {
+			fail(fmt.Sprintf("unknown histogram bucket %q, use minute, hour or day", *bucket))
+		}
+		cfg.Bucket = width
+	}
+	now := time.Now()
+	for _, bound := range []struct {
+		value string
+		dest  *time.Time
+	}{{*since, &cfg.Since}, {*until, &cfg.Until}} {
+		if bound.value == "" {
+			continue
+		}
+		if *bound.dest, err = parseTimeBound(bound.value, now); err != nil {
+			fail(err)
+		}
+	}
 
-	infoCount := 0
-	warningCount := 0
-	errorCount := 0
-	totalLines := 0
+	if *followFile {
+		if flags.NArg() != 1 || flags.Arg(0) == stdinPath {
+			fail("--follow takes a single file")
+		}
+		if *refresh <= 0 {
+			fail("--refresh must be positive")
+		}
+		logFilePath := flags.Arg(0)
+		fl, err := openFollower(logFilePath)
+		if err != nil {
+			fail(fmt.Sprintf("Error while opening file %s: %v", logFilePath, err))
+		}
+		defer fl.Close()
 
-	scanner := bufio.NewScanner(file)
-	// Iterate over each line in the file
-	for scanner.Scan() {
-		line := scanner.Text()
-		totalLines++
+		var alerts *notifier
+		if cfg.Rules != nil && cfg.Rules.Webhook != "" {
+			alerts = newNotifier(cfg.Rules.Webhook, logFilePath)
+		}
 
-		// Use switch to categorize log levels
-		switch {
-		case strings.Contains(line, "[INFO]"):
-			infoCount++
-		case strings.Contains(line, "[WARNING]"):
-			warningCount++
-		case strings.Contains(line, "[ERROR]"):
-			errorCount++
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		c := newCounter(cfg)
+		if err := followLog(ctx, fl, c, *refresh, render, alerts, os.Stdout); err != nil {
+			fail(fmt.Sprintf("Error while following file %s: %v", logFilePath, err))
+		}
+		if checkRules(cfg.Rules, c.analysis(), time.Now()) {
+			os.Exit(1)
 		}
+		return
+	}
+
+	paths, err := expandInputs(flags.Args())
+	if err != nil {
+		fail(err)
+	}
+
+	files, result, err := analyzeInputs(paths, cfg)
+	if err != nil {
+		fail(fmt.Sprintf("Error while reading logs: %v", err))
 	}
 
-	// Print the summary report
-	fmt.Printf("Log Analysis of file: %s\n\n", logFilePath)
-	fmt.Printf("INFO: %d entries\n", infoCount)
-	fmt.Printf("WARNING: %d entries\n", warningCount)
-	fmt.Printf("ERROR: %d entries\n", errorCount)
+	if cfg.Print != nil {
+		if checkRules(cfg.Rules, result, time.Time{}) {
+			os.Exit(1)
+		}
+		return
+	}
+	r := newReport(paths, files, result, levels, *top, time.Now())
+	if baseline != nil {
+		r.Comparison = compareReports(*baseline, *compareFile, result, levels, *top)
+	}
+	if err := render(os.Stdout, r); err != nil {
+		fail(fmt.Sprintf("Error while writing the report: %v", err))
+	}
 
-	// Bonus: Count total lines and calculate percentages
-	fmt.Printf("\nTotal log lines: %d\n", totalLines)
-	if totalLines > 0 {
-		fmt.Printf("INFO percentage: %.2f%%\n", float64(infoCount)/float64(totalLines)*100)
-		fmt.Printf("WARNING percentage: %.2f%%\n", float64(warningCount)/float64(totalLines)*100)
-		fmt.Printf("ERROR percentage: %.2f%%\n", float64(errorCount)/float64(totalLines)*100)
+	failed := checkRules(cfg.Rules, result, time.Time{})
+	if regressionThreshold >= 0 {
+		if regressions := r.Comparison.regressions(regressionThreshold, levels); len(regressions) > 0 {
+			fmt.Fprintf(os.Stderr, "Regressions of more than %s%% over %s:\n  %s\n", *maxRegression, *compareFile, strings.Join(regressions, "\n  "))
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
 	}
+}
+
+// fail reports an error on stderr and exits with status 2, apart from the status 1 of
+// violated rules, so that a broken configuration or an unreadable log never passes a check.
+func fail(err any) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
 
-	// Added time-based message
-	fmt.Printf("\nAnalyzed at: %s\n", time.Now().Format("2025-06-12 15:04:05"))
+// checkRules lists the violated rules on stderr and reports whether there are any.
+func checkRules(rs *ruleSet, result analysis, now time.Time) bool {
+	return rs != nil && writeViolations(os.Stderr, rs.evaluate(result, now))
 }
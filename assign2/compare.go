@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// comparison is the change from a baseline report, saved earlier with --output json, to this run.
+type comparison struct {
+	Baseline   string           `json:"baseline"`
+	BaselineAt time.Time        `json:"baseline_analyzed_at"`
+	Levels     []countDelta     `json:"levels"`
+	Signatures []signatureDelta `json:"signatures"` // of ERROR and more severe levels, new ones first
+}
+
+// countDelta is how one count changed. Change is the percentage change, nil when the baseline count is zero.
+type countDelta struct {
+	Level    string   `json:"level"`
+	Baseline int      `json:"baseline"`
+	Current  int      `json:"current"`
+	Delta    int      `json:"delta"`
+	Change   *float64 `json:"change"`
+}
+
+// signatureDelta is how the count of one signature changed. New marks a signature the baseline does not have.
+type signatureDelta struct {
+	countDelta
+	Text string `json:"signature"`
+	New  bool   `json:"new"`
+}
+
+func newCountDelta(level string, baseline, current int) countDelta {
+	d := countDelta{Level: level, Baseline: baseline, Current: current, Delta: current - baseline}
+	if baseline > 0 {
+		change := float64(d.Delta) / float64(baseline) * 100
+		d.Change = &change
+	}
+	return d
+}
+
+// loadBaseline reads a report saved with --output json.
+func loadBaseline(path string) (report, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report{}, fmt.Errorf("reading baseline: %w", err)
+	}
+	var r report
+	if err := json.Unmarshal(data, &r); err != nil {
+		return report{}, fmt.Errorf("reading baseline %s, which should be a report saved with --output json: %w", path, err)
+	}
+	return r, nil
+}
+
+// compareReports compares a run with its baseline. Signatures are compared for ERROR and the
+// levels above it, or for every level when the table has no ERROR: every signature the baseline
+// lists, and the top signatures of this run. The baseline only knows the signatures it was saved
+// with, so save it with a --top at least as large as this run's.
+func compareReports(baseline report, path string, result analysis, levels *levelTable, top int) *comparison {
+	c := &comparison{Baseline: path, BaselineAt: baseline.AnalyzedAt}
+
+	before := map[string]int{}
+	var names []string
+	for _, l := range baseline.Levels {
+		before[l.Level] = l.Count
+		names = append(names, l.Level)
+	}
+	for _, name := range append(append([]string{}, levels.names...), unclassified) {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if before[name] > 0 || result.Levels[name] > 0 {
+			c.Levels = append(c.Levels, newCountDelta(name, before[name], result.Levels[name]))
+		}
+	}
+
+	lowest := levels.rank(levels.classify("ERROR"))
+	isError := func(level string) bool { return levels.rank(level) >= lowest }
+
+	type key struct{ level, text string }
+	seen := map[key]bool{}
+	for _, sig := range baseline.Signatures {
+		if !isError(sig.Level) {
+			continue
+		}
+		current := 0
+		if s := result.Signatures[sig.Level][sig.Text]; s != nil {
+			current = s.Count
+		}
+		seen[key{sig.Level, sig.Text}] = true
+		c.Signatures = append(c.Signatures, signatureDelta{countDelta: newCountDelta(sig.Level, sig.Count, current), Text: sig.Text})
+	}
+	for _, sig := range result.Signatures.report(levels, top) {
+		if !isError(sig.Level) || seen[key{sig.Level, sig.Text}] {
+			continue
+		}
+		c.Signatures = append(c.Signatures, signatureDelta{countDelta: newCountDelta(sig.Level, 0, sig.Count), Text: sig.Text, New: true})
+	}
+
+	sort.SliceStable(c.Signatures, func(i, j int) bool {
+		a, b := c.Signatures[i], c.Signatures[j]
+		if a.New != b.New {
+			return a.New
+		}
+		return a.Delta > b.Delta
+	})
+	return c
+}
+
+// regressions lists the counts of ERROR and more severe levels, and of their signatures, that grew
+// by more than threshold percent. A count the baseline does not have always counts as grown.
+func (c *comparison) regressions(threshold float64, levels *levelTable) []string {
+	lowest := levels.rank(levels.classify("ERROR"))
+	grew := func(d countDelta) bool {
+		return d.Delta > 0 && (d.Change == nil || *d.Change > threshold)
+	}
+
+	var out []string
+	for _, d := range c.Levels {
+		if levels.rank(d.Level) >= lowest && grew(d) {
+			out = append(out, fmt.Sprintf("%s entries: %d to %d (%s)", d.Level, d.Baseline, d.Current, d.change()))
+		}
+	}
+	for _, d := range c.Signatures {
+		if grew(d.countDelta) {
+			out = append(out, fmt.Sprintf("%s signature %q: %d to %d (%s)", d.Level, d.Text, d.Baseline, d.Current, d.change()))
+		}
+	}
+	return out
+}
+
+// change formats the percentage change, or says the count is new.
+func (d countDelta) change() string {
+	switch {
+	case d.Change != nil:
+		return strconv.FormatFloat(*d.Change, 'f', 1, 64) + "%"
+	case d.Current > 0:
+		return "new"
+	}
+	return "-"
+}
+
+// write prints the comparison as tables, with NEW in front of signatures the baseline does not have.
+func (c *comparison) write(w io.Writer) {
+	fmt.Fprintf(w, "\nCompared with %s (analyzed at %s):\n", c.Baseline, c.BaselineAt.Format(time.DateTime))
+	fmt.Fprintf(w, "%-12s %9s %9s %7s %8s\n", "Level", "Baseline", "Current", "Delta", "Change")
+	for _, d := range c.Levels {
+		fmt.Fprintf(w, "%-12s %9d %9d %+7d %8s\n", d.Level, d.Baseline, d.Current, d.Delta, signedChange(d))
+	}
+
+	if len(c.Signatures) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\nError signatures:\n%-3s %-12s %9s %9s %7s %8s  %s\n", "", "Level", "Baseline", "Current", "Delta", "Change", "Signature")
+	for _, d := range c.Signatures {
+		marker := ""
+		if d.New {
+			marker = "NEW"
+		}
+		fmt.Fprintf(w, "%-3s %-12s %9d %9d %+7d %8s  %s\n", marker, d.Level, d.Baseline, d.Current, d.Delta, signedChange(d.countDelta), d.Text)
+	}
+}
+
+// signedChange is change with a plus sign on growth, for the tables.
+func signedChange(d countDelta) string {
+	if d.Change != nil && *d.Change > 0 {
+		return "+" + d.change()
+	}
+	return d.change()
+}
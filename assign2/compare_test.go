@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompareReports(t *testing.T) {
+	levels := defaultLevelTable()
+	f, err := os.Open("testdata/log.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := analyze(f, config{Levels: levels})
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Save the baseline the way --output json does and read it back.
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writeJSONReport(out, newReport([]string{"log.txt"}, nil, before, levels, 10, time.Now())); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+	baseline, err := loadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	today := strings.Join([]string{
+		"[INFO] Server started",
+		"[ERROR] Failed to connect to DB",
+		"[ERROR] Failed to connect to DB",
+		"[ERROR] Failed to connect to DB",
+		"[ERROR] Disk 3 full",
+		"[FATAL] out of memory",
+	}, "\n")
+	after, err := analyze(strings.NewReader(today), config{Levels: levels})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := compareReports(baseline, path, after, levels, 10)
+
+	var got []string
+	for _, d := range c.Levels {
+		got = append(got, d.Level+" "+signedChange(d))
+	}
+	if want := "INFO -75.0%, WARNING -100.0%, ERROR 0.0%, FATAL new"; strings.Join(got, ", ") != want {
+		t.Errorf("Expected level changes %s, got %s", want, strings.Join(got, ", "))
+	}
+
+	got = nil
+	for _, d := range c.Signatures {
+		got = append(got, d.Text+" "+signedChange(d.countDelta))
+	}
+	if want := "out of memory new, Disk <num> full new, Failed to connect to DB +50.0%, Timeout occurred -100.0%"; strings.Join(got, ", ") != want {
+		t.Errorf("Expected signature changes %s, got %s", want, strings.Join(got, ", "))
+	}
+	if !c.Signatures[0].New || c.Signatures[2].New {
+		t.Errorf("Expected only the signatures missing from the baseline to be new, got %+v", c.Signatures)
+	}
+
+	tests := []struct {
+		threshold float64
+		want      int
+	}{{0, 4}, {49, 4}, {50, 3}}
+	for _, tt := range tests {
+		if got := c.regressions(tt.threshold, levels); len(got) != tt.want {
+			t.Errorf("regressions(%v): expected %d, got %q", tt.threshold, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// entryRules says which lines continue the entry before them, so that a stack trace or
+// a panic counts once, under the level of the line that starts it.
+type entryRules struct {
+	starts   []*regexp.Regexp // lines matching one of these start an entry; none for lines the layout reads
+	indent   bool             // indented lines continue an entry, instead of lines that do not start one
+	maxLines int              // an entry this long ends, and the next line starts another
+}
+
+// newEntryRules builds the rules for --multiline, which is off, start or indent. Patterns given
+// with --entry-start turn start mode on when --multiline is off. It returns nil for one entry per line.
+func newEntryRules(mode string, starts []string, maxLines int) (*entryRules, error) {
+	if mode == "off" && len(starts) > 0 {
+		mode = "start"
+	}
+	switch mode {
+	case "off":
+		return nil, nil
+	case "start", "indent":
+	default:
+		return nil, fmt.Errorf("unknown multiline mode %q, use off, start or indent", mode)
+	}
+	if maxLines < 1 {
+		return nil, fmt.Errorf("--max-entry-lines must be at least 1")
+	}
+
+	r := &entryRules{indent: mode == "indent", maxLines: maxLines}
+	for _, expr := range starts {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry start %q: %w", expr, err)
+		}
+		r.starts = append(r.starts, re)
+	}
+	return r, nil
+}
+
+// continues reports whether line belongs to the entry before it.
+func (r *entryRules) continues(line string, parser LineParser) bool {
+	if r.indent {
+		return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+	}
+	if len(r.starts) == 0 {
+		_, ok := parser.Parse(line)
+		return !ok
+	}
+	for _, re := range r.starts {
+		if re.MatchString(line) {
+			return false
+		}
+	}
+	return true
+}
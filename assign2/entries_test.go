@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEntries(t *testing.T) {
+	tests := []struct {
+		name          string
+		mode          string
+		starts        []string
+		maxLines      int
+		wantLines     int
+		wantError     int
+		wantUnclassed int
+	}{
+		{name: "Off", mode: "off", maxLines: 1000, wantLines: 12, wantError: 2, wantUnclassed: 8},
+		{name: "Start", mode: "start", maxLines: 1000, wantLines: 4, wantError: 2},
+		{name: "Start_Pattern", mode: "off", starts: []string{`^\d{4}-`, `^goroutine `}, maxLines: 1000, wantLines: 5, wantError: 2, wantUnclassed: 1},
+		{name: "Indent", mode: "indent", maxLines: 1000, wantLines: 8, wantError: 2, wantUnclassed: 4},
+		{name: "Max_Lines", mode: "start", maxLines: 3, wantLines: 6, wantError: 2, wantUnclassed: 2},
+	}
+
+	for _, tt := range tests {
+		rules, err := newEntryRules(tt.mode, tt.starts, tt.maxLines)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		f, err := os.Open("testdata/multiline.log")
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := analyze(f, config{Levels: defaultLevelTable(), Entries: rules, Workers: 4})
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		if result.Lines != tt.wantLines || result.Read != 12 {
+			t.Errorf("%s: expected %d entries over 12 lines, got %d over %d", tt.name, tt.wantLines, result.Lines, result.Read)
+		}
+		if result.Levels["ERROR"] != tt.wantError || result.Levels[unclassified] != tt.wantUnclassed {
+			t.Errorf("%s: expected %d ERROR and %d unclassified entries, got %v", tt.name, tt.wantError, tt.wantUnclassed, result.Levels)
+		}
+	}
+
+	for _, bad := range []struct {
+		mode     string
+		starts   []string
+		maxLines int
+	}{{"lines", nil, 10}, {"start", []string{"("}, 10}, {"indent", nil, 0}} {
+		if _, err := newEntryRules(bad.mode, bad.starts, bad.maxLines); err == nil {
+			t.Errorf("newEntryRules(%q, %q, %d): expected an error", bad.mode, bad.starts, bad.maxLines)
+		}
+	}
+}
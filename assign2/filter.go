@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// stringList is a flag that can be given more than once.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ", ") }
+
+func (l *stringList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+// fieldMatch is a --field condition: key=value, or key!=value to leave such lines out.
+type fieldMatch struct {
+	key, value string
+	negate     bool
+}
+
+// filter decides which lines are counted. Lines it drops are only tallied as filtered out.
+type filter struct {
+	include  []*regexp.Regexp // the message must match one of these, when there are any
+	exclude  []*regexp.Regexp // the message must match none of these
+	fields   []fieldMatch     // every one must hold
+	minLevel int              // lowest rank kept, -1 to keep unclassified lines too
+	maxLevel int              // highest rank kept
+}
+
+// newFilter builds a filter from the command line, or returns nil when no option asks for one.
+func newFilter(include, exclude, fields []string, minLevel, maxLevel string, levels *levelTable) (*filter, error) {
+	f := &filter{minLevel: -1, maxLevel: len(levels.names) - 1}
+	for _, list := range []struct {
+		exprs []string
+		dest  *[]*regexp.Regexp
+	}{{include, &f.include}, {exclude, &f.exclude}} {
+		for _, expr := range list.exprs {
+			re, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", expr, err)
+			}
+			*list.dest = append(*list.dest, re)
+		}
+	}
+
+	for _, field := range fields {
+		key, value, ok := strings.Cut(field, "=")
+		m := fieldMatch{key: strings.ToLower(strings.TrimSpace(key)), value: value}
+		if strings.HasSuffix(m.key, "!") {
+			m.key, m.negate = strings.TrimSpace(strings.TrimSuffix(m.key, "!")), true
+		}
+		if !ok || m.key == "" {
+			return nil, fmt.Errorf("invalid field condition %q, use key=value or key!=value", field)
+		}
+		f.fields = append(f.fields, m)
+	}
+
+	for _, bound := range []struct {
+		name string
+		dest *int
+	}{{minLevel, &f.minLevel}, {maxLevel, &f.maxLevel}} {
+		if bound.name == "" {
+			continue
+		}
+		level := levels.classify(bound.name)
+		if level == unclassified {
+			return nil, fmt.Errorf("unknown level %q, use one of %s", bound.name, strings.Join(levels.names, ", "))
+		}
+		*bound.dest = levels.rank(level)
+	}
+	if f.minLevel > f.maxLevel {
+		return nil, fmt.Errorf("--min-level is above --max-level")
+	}
+
+	if len(f.include)+len(f.exclude)+len(f.fields) == 0 && minLevel == "" && maxLevel == "" {
+		return nil, nil
+	}
+	return f, nil
+}
+
+// keep reports whether a line passes the filter, given its parsed entry, its canonical
+// level and the message the patterns are matched against.
+func (f *filter) keep(e Entry, level, message string, levels *levelTable) bool {
+	if rank := levels.rank(level); rank < f.minLevel || rank > f.maxLevel {
+		return false
+	}
+
+	for _, m := range f.fields {
+		value, ok := e.field(m.key)
+		if (ok && value == m.value) == m.negate {
+			return false
+		}
+	}
+
+	for _, re := range f.exclude {
+		if re.MatchString(message) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, re := range f.include {
+		if re.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPrinter prints the lines that are counted, with the lines around them, the way grep -n -C does:
+// "12:" before a match, "11-" before a context line and, when there is context, "--" between
+// groups that are not adjacent.
+type matchPrinter struct {
+	w       io.Writer
+	context int
+
+	prefix  string   // file name and colon, when several files are read
+	line    int      // number of the last line seen
+	before  []string // up to context lines before the current one
+	after   int      // context lines still to print after the last match
+	printed int      // number of the last line printed from this input, zero for none
+	any     bool     // whether anything was printed from any input
+}
+
+func newMatchPrinter(w io.Writer, context int) *matchPrinter {
+	return &matchPrinter{w: w, context: max(context, 0)}
+}
+
+// start resets the printer for a new input, prefixing its lines with the name when there are several.
+func (p *matchPrinter) start(name string, several bool) {
+	p.prefix = ""
+	if several {
+		p.prefix = name + ":"
+	}
+	p.line, p.before, p.after, p.printed = 0, nil, 0, 0
+}
+
+// add takes the next line of the input and whether it is a match.
+func (p *matchPrinter) add(text string, match bool) {
+	p.line++
+	switch {
+	case match:
+		adjacent := p.printed > 0 && p.line-len(p.before) <= p.printed+1
+		if p.context > 0 && p.any && !adjacent {
+			fmt.Fprintln(p.w, "--")
+		}
+		for i, b := range p.before {
+			fmt.Fprintf(p.w, "%s%d-%s\n", p.prefix, p.line-len(p.before)+i, b)
+		}
+		fmt.Fprintf(p.w, "%s%d:%s\n", p.prefix, p.line, text)
+		p.before, p.after, p.printed, p.any = p.before[:0], p.context, p.line, true
+	case p.after > 0:
+		fmt.Fprintf(p.w, "%s%d-%s\n", p.prefix, p.line, text)
+		p.after--
+		p.printed = p.line
+	case p.context > 0:
+		if len(p.before) == p.context {
+			p.before = p.before[1:]
+		}
+		p.before = append(p.before, text)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	log := strings.Join([]string{
+		`time=2024-06-12T10:00:00Z level=info component=api msg="request 1 ok" user=alice`,
+		`time=2024-06-12T10:00:01Z level=error component=db msg="query failed" user=bob`,
+		`time=2024-06-12T10:00:02Z level=info component=api msg="request 2 ok" user=bob`,
+		`time=2024-06-12T10:00:03Z level=warn component=api msg="slow request 3" user=alice`,
+		`time=2024-06-12T10:00:04Z level=debug component=api msg="request 4 ok"`,
+		`not logfmt at all`,
+	}, "\n")
+
+	tests := []struct {
+		name                       string
+		include, exclude, fields   []string
+		minLevel, maxLevel         string
+		wantLines, wantFilteredOut int
+	}{
+		{name: "Include", include: []string{`^request \d+`}, wantLines: 3, wantFilteredOut: 3},
+		{name: "Exclude", exclude: []string{`ok$`, `^not`}, wantLines: 2, wantFilteredOut: 4},
+		{name: "Field", fields: []string{"user=alice"}, wantLines: 2, wantFilteredOut: 4},
+		{name: "Field_Negated", fields: []string{"user!=alice", "component=api"}, wantLines: 2, wantFilteredOut: 4},
+		{name: "Source_Field", fields: []string{"source=db"}, wantLines: 1, wantFilteredOut: 5},
+		{name: "Min_Level", minLevel: "warn", wantLines: 2, wantFilteredOut: 4},
+		{name: "Level_Range", minLevel: "DEBUG", maxLevel: "INFO", wantLines: 3, wantFilteredOut: 3},
+		{name: "Max_Level_Keeps_Unclassified", maxLevel: "DEBUG", wantLines: 2, wantFilteredOut: 4},
+	}
+
+	levels := defaultLevelTable()
+	for _, tt := range tests {
+		f, err := newFilter(tt.include, tt.exclude, tt.fields, tt.minLevel, tt.maxLevel, levels)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		result, err := analyze(strings.NewReader(log), config{Parser: logfmtParser{}, Levels: levels, Filter: f})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if result.Lines != tt.wantLines || result.FilteredOut != tt.wantFilteredOut {
+			t.Errorf("%s: expected %d lines counted and %d filtered out, got %d and %d", tt.name, tt.wantLines, tt.wantFilteredOut, result.Lines, result.FilteredOut)
+		}
+	}
+
+	for _, bad := range []struct {
+		include, fields    []string
+		minLevel, maxLevel string
+	}{
+		{include: []string{"("}},
+		{fields: []string{"user"}},
+		{fields: []string{"=alice"}},
+		{minLevel: "LOUD"},
+		{minLevel: "ERROR", maxLevel: "INFO"},
+	} {
+		if _, err := newFilter(bad.include, nil, bad.fields, bad.minLevel, bad.maxLevel, levels); err == nil {
+			t.Errorf("newFilter(%+v): expected an error", bad)
+		}
+	}
+	if f, err := newFilter(nil, nil, nil, "", "", levels); f != nil || err != nil {
+		t.Errorf("Expected no filter without options, got %+v, %v", f, err)
+	}
+}
+
+func TestMatchPrinter(t *testing.T) {
+	var out strings.Builder
+	p := newMatchPrinter(&out, 1)
+	for _, name := range []string{"a.log", "b.log"} {
+		p.start(name, true)
+		for i, line := range []string{"one", "two", "three", "four", "five", "six"} {
+			p.add(line, i == 1 || i == 2 || (name == "a.log" && i == 5))
+		}
+	}
+
+	want := strings.Join([]string{
+		"a.log:1-one",
+		"a.log:2:two",
+		"a.log:3:three",
+		"a.log:4-four",
+		"a.log:5-five",
+		"a.log:6:six",
+		"--",
+		"b.log:1-one",
+		"b.log:2:two",
+		"b.log:3:three",
+		"b.log:4-four",
+	}, "\n") + "\n"
+	if out.String() != want {
+		t.Errorf("Expected:\n%s\ngot:\n%s", want, out.String())
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+)
+
+// followPollInterval is how often a followed file is checked for new lines, at most.
+const followPollInterval = 250 * time.Millisecond
+
+// follower reads the lines appended to a file, like tail -f. It starts over when the file is
+// truncated, and moves to the new file when the path is renamed away and recreated.
+type follower struct {
+	path    string
+	f       *os.File
+	r       *bufio.Reader
+	info    os.FileInfo // identity of the open file, to notice rotation
+	offset  int64
+	partial []byte // text after the last newline, waiting for the rest of its line
+}
+
+// openFollower opens path for following from its first line.
+func openFollower(path string) (*follower, error) {
+	fl := &follower{path: path}
+	if err := fl.open(); err != nil {
+		return nil, err
+	}
+	return fl, nil
+}
+
+func (fl *follower) open() error {
+	f, err := os.Open(fl.path)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	fl.f, fl.info, fl.offset, fl.partial = f, info, 0, nil
+	if fl.r == nil {
+		fl.r = bufio.NewReader(f)
+	} else {
+		fl.r.Reset(f)
+	}
+	return nil
+}
+
+// poll calls fn with every complete line written since the last call.
+func (fl *follower) poll(fn func(string)) error {
+	// Drain the open file first: after a rotation it may still have lines written before the rename.
+	if err := fl.read(fn); err != nil {
+		return err
+	}
+
+	info, err := fl.f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() < fl.offset {
+		// Truncated in place: what is there now was written from the start.
+		if _, err := fl.f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		fl.r.Reset(fl.f)
+		fl.offset, fl.partial = 0, nil
+		return fl.read(fn)
+	}
+
+	current, err := os.Stat(fl.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		// Renamed away and not recreated yet; keep the old file until the new one shows up.
+		return nil
+	}
+	if err != nil || os.SameFile(current, fl.info) {
+		return err
+	}
+
+	// Rotated: the last line of the old file is complete even without a newline.
+	if len(fl.partial) > 0 {
+		fn(string(fl.partial))
+	}
+	fl.f.Close()
+	if err := fl.open(); err != nil {
+		return err
+	}
+	return fl.read(fn)
+}
+
+// read calls fn with every complete line between the offset and the end of the open file,
+// streaming them so that a large file is never held in memory at once.
+func (fl *follower) read(fn func(string)) error {
+	for {
+		chunk, err := fl.r.ReadSlice('\n')
+		fl.offset += int64(len(chunk))
+		if err != nil {
+			fl.partial = append(fl.partial, chunk...)
+			if errors.Is(err, bufio.ErrBufferFull) {
+				continue
+			}
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+
+		line := chunk[:len(chunk)-1]
+		if len(fl.partial) > 0 {
+			line = append(fl.partial, line...)
+			fl.partial = nil
+		}
+		fn(strings.TrimSuffix(string(line), "\r"))
+	}
+}
+
+// Close closes the followed file.
+func (fl *follower) Close() error {
+	return fl.f.Close()
+}
+
+// followLog counts the lines of a followed file until ctx is done, printing the running
+// counts and rates every refresh. With alerts set, the rules are checked every refresh too,
+// and a change in any of them is posted to the webhook. The full report is rendered with
+// render when it stops, unless the counted lines are being printed instead.
+func followLog(ctx context.Context, fl *follower, c *counter, refresh time.Duration, render reportWriter, alerts *notifier, out io.Writer) error {
+	poll := time.NewTicker(min(refresh, followPollInterval))
+	defer poll.Stop()
+
+	previous, lastPrint := map[string]int{}, time.Now()
+
+	for {
+		if err := fl.poll(c.add); err != nil {
+			return err
+		}
+
+		// Nothing is printed until there is a line to detect the layout from, and
+		// only the matching lines are printed when they are asked for.
+		if now := time.Now(); now.Sub(lastPrint) >= refresh && (c.parser != nil || c.sampled > 0) {
+			current := c.analysis()
+			if c.cfg.Print == nil {
+				writeProgress(out, now, current, previous, now.Sub(lastPrint), c.cfg.Levels)
+			}
+			previous, lastPrint = copyCounts(current.Levels), now
+
+			if alerts != nil {
+				if err := alerts.notify(c.cfg.Rules.evaluate(current, now), now); err != nil {
+					fmt.Fprintln(out, err)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			err := fl.poll(c.add)
+			c.close()
+			result := c.analysis()
+			if c.cfg.Print != nil {
+				return err
+			}
+			r := newReport([]string{fl.path}, nil, result, c.cfg.Levels, c.cfg.Top, time.Now())
+			if renderErr := render(out, r); err == nil {
+				err = renderErr
+			}
+			return err
+		case <-poll.C:
+		}
+	}
+}
+
+// writeProgress prints one line with the running total and the count of every level seen,
+// each with its change and rate per second since the previous line.
+func writeProgress(w io.Writer, now time.Time, current analysis, previous map[string]int, elapsed time.Duration, levels *levelTable) {
+	previousTotal := 0
+	for _, n := range previous {
+		previousTotal += n
+	}
+
+	rate := func(delta int) string {
+		return fmt.Sprintf("%+d, %.1f/s", delta, float64(delta)/elapsed.Seconds())
+	}
+
+	parts := []string{now.Format(time.TimeOnly), fmt.Sprintf("lines %d (%s)", current.Lines, rate(current.Lines-previousTotal))}
+	for _, name := range append(append([]string{}, levels.names...), unclassified) {
+		if n := current.Levels[name]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d (%s)", name, n, rate(n-previous[name])))
+		}
+	}
+	fmt.Fprintln(w, strings.Join(parts, "  "))
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	out := make(map[string]int, len(counts))
+	for k, v := range counts {
+		out[k] = v
+	}
+	return out
+}
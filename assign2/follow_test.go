@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFollower(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	appendFile(t, path, "[INFO] one\n")
+
+	fl, err := openFollower(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fl.Close()
+
+	steps := []struct {
+		name   string
+		change func()
+		want   []string
+	}{
+		{"Existing_Lines", func() {}, []string{"[INFO] one"}},
+		{"Nothing_New", func() {}, nil},
+		{"Long_Line", func() { appendFile(t, path, "[INFO] "+strings.Repeat("x", 10000)+"\n") }, []string{"[INFO] " + strings.Repeat("x", 10000)}},
+		{"Partial_Line", func() { appendFile(t, path, "[WARN] two\n[ERR") }, []string{"[WARN] two"}},
+		{"Rest_Of_Line", func() { appendFile(t, path, "OR] three\n") }, []string{"[ERROR] three"}},
+		{
+			name: "Truncated",
+			change: func() {
+				if err := os.Truncate(path, 0); err != nil {
+					t.Fatal(err)
+				}
+			},
+		},
+		{"After_Truncation", func() { appendFile(t, path, "[INFO] four\n") }, []string{"[INFO] four"}},
+		{
+			name: "Rotated_Not_Recreated",
+			change: func() {
+				appendFile(t, path, "[INFO] five\n")
+				if err := os.Rename(path, path+".1"); err != nil {
+					t.Fatal(err)
+				}
+				appendFile(t, path+".1", "[ERROR] six, written late to the old file")
+			},
+			want: []string{"[INFO] five"},
+		},
+		{"Recreated", func() { appendFile(t, path, "[INFO] seven\n") }, []string{"[ERROR] six, written late to the old file", "[INFO] seven"}},
+		{"Following_New_File", func() { appendFile(t, path+".1", "[INFO] ignored\n"); appendFile(t, path, "[WARN] eight\n") }, []string{"[WARN] eight"}},
+	}
+
+	for _, step := range steps {
+		step.change()
+		var got []string
+		err := fl.poll(func(line string) { got = append(got, line) })
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if !reflect.DeepEqual(got, step.want) {
+			t.Errorf("%s: expected %q, got %q", step.name, step.want, got)
+		}
+	}
+}
+
+// progressBuffer collects what followLog prints, so that a test can wait for a line to appear
+// instead of sleeping and hoping the follower has caught up.
+type progressBuffer struct {
+	mu      sync.Mutex
+	b       strings.Builder
+	written chan struct{}
+}
+
+func (p *progressBuffer) Write(data []byte) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	select {
+	case p.written <- struct{}{}:
+	default:
+	}
+	return p.b.Write(data)
+}
+
+func (p *progressBuffer) String() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.b.String()
+}
+
+// waitFor blocks until the output contains text.
+func (p *progressBuffer) waitFor(t *testing.T, text string) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	for !strings.Contains(p.String(), text) {
+		select {
+		case <-p.written:
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %q, got:\n%s", text, p.String())
+		}
+	}
+}
+
+func TestFollowLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "service.log")
+	appendFile(t, path, "[INFO] up\n[ERROR] down\n")
+
+	fl, err := openFollower(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fl.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	out := &progressBuffer{written: make(chan struct{}, 1)}
+	go func() {
+		done <- followLog(ctx, fl, newCounter(config{Levels: defaultLevelTable()}), time.Millisecond, writeTextReport, nil, out)
+	}()
+
+	out.waitFor(t, "lines 2 (+2, ")
+	appendFile(t, path, "[WARN] slow\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	appendFile(t, path, "[ERROR] down again\n")
+	out.waitFor(t, "lines 4 (")
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, want := range []string{"lines 2 (+2, ", "INFO: 1 entries", "WARNING: 1 entries", "ERROR: 2 entries", "Total log lines: 4"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected the output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// bracketParser reads the analyzer's original layout, "[LEVEL] message", optionally preceded by
+// a timestamp and with an optional "[source]" tag after the level. Only a level at the start
+// counts, so "[INFO] retrying after [ERROR]" is an INFO line.
+type bracketParser struct{}
+
+var bracketLine = regexp.MustCompile(`^(?:(\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:[.,]\d+)?(?:Z|[+-]\d{2}:?\d{2})?)\s+)?\[([A-Za-z]+)\]\s*(?:\[([^\]]+)\]\s*)?(.*)$`)
+
+func (bracketParser) Name() string { return "bracket" }
+
+func (bracketParser) Parse(line string) (Entry, bool) {
+	m := bracketLine.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false
+	}
+
+	e := Entry{Level: strings.ToUpper(m[2]), Source: m[3], Message: m[4]}
+	if m[1] != "" {
+		e.Time, _ = parseTimestamp(strings.Replace(m[1], ",", ".", 1))
+	}
+	return e, true
+}
+
+// logfmtParser reads key=value lines such as `time=2024-06-12T10:00:00Z level=info msg="Server started"`.
+type logfmtParser struct{}
+
+func (logfmtParser) Name() string { return "logfmt" }
+
+func (logfmtParser) Parse(line string) (Entry, bool) {
+	fields, ok := splitLogfmt(line)
+	if !ok || len(fields) < 2 {
+		return Entry{}, false
+	}
+	return entryFromFields(fields)
+}
+
+// splitLogfmt splits a logfmt line into its pairs, with lower-case keys. Values may be
+// double-quoted with Go escapes. It reports false for any text that is not a pair.
+func splitLogfmt(line string) (map[string]string, bool) {
+	fields := map[string]string{}
+	for rest := strings.TrimSpace(line); rest != ""; rest = strings.TrimLeft(rest, " \t") {
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 || strings.ContainsAny(rest[:eq], " \t\"") {
+			return nil, false
+		}
+		key := strings.ToLower(rest[:eq])
+		rest = rest[eq+1:]
+
+		if strings.HasPrefix(rest, `"`) {
+			quoted, err := strconv.QuotedPrefix(rest)
+			if err != nil {
+				return nil, false
+			}
+			value, _ := strconv.Unquote(quoted)
+			fields[key], rest = value, rest[len(quoted):]
+			continue
+		}
+
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			end = len(rest)
+		}
+		fields[key], rest = rest[:end], rest[end:]
+	}
+	return fields, true
+}
+
+// jsonParser reads JSON-lines logs, one object per line.
+type jsonParser struct{}
+
+func (jsonParser) Name() string { return "json" }
+
+func (jsonParser) Parse(line string) (Entry, bool) {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "{") {
+		return Entry{}, false
+	}
+
+	var raw map[string]any
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return Entry{}, false
+	}
+
+	fields := make(map[string]string, len(raw))
+	for k, v := range raw {
+		switch v := v.(type) {
+		case string:
+			fields[strings.ToLower(k)] = v
+		case float64, bool:
+			fields[strings.ToLower(k)] = fmt.Sprint(v)
+		}
+	}
+	return entryFromFields(fields)
+}
+
+// syslogParser reads RFC 5424 lines and the older BSD layout of RFC 3164, with or without
+// the leading <PRI>. The level is the severity encoded in PRI, when there is one.
+type syslogParser struct{}
+
+var (
+	syslog5424Line = regexp.MustCompile(`^<(\d{1,3})>1 (\S+) (\S+) (\S+) (\S+) (\S+) (-|(?:\[(?:[^\]\\]|\\.)*\])+)(?: (.*))?$`)
+	syslog3164Line = regexp.MustCompile(`^(?:<(\d{1,3})>)?([A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2}) (\S+) ([^:\[\s]+)(?:\[\d+\])?: ?(.*)$`)
+)
+
+// syslogSeverities names the severities 0 to 7 of the PRI field.
+var syslogSeverities = []string{"EMERG", "ALERT", "CRIT", "ERR", "WARNING", "NOTICE", "INFO", "DEBUG"}
+
+func (syslogParser) Name() string { return "syslog" }
+
+func (syslogParser) Parse(line string) (Entry, bool) {
+	if m := syslog5424Line.FindStringSubmatch(line); m != nil {
+		level, ok := syslogLevel(m[1])
+		if !ok {
+			return Entry{}, false
+		}
+		e := Entry{Level: level, Source: nilValue(m[4]), Message: strings.TrimPrefix(m[8], "\ufeff")}
+		if e.Source == "" {
+			e.Source = nilValue(m[3])
+		}
+		e.Time, _ = parseTimestamp(nilValue(m[2]))
+		return e, true
+	}
+
+	if m := syslog3164Line.FindStringSubmatch(line); m != nil {
+		e := Entry{Source: m[4], Message: m[5]}
+		if m[1] != "" {
+			level, ok := syslogLevel(m[1])
+			if !ok {
+				return Entry{}, false
+			}
+			e.Level = level
+		}
+		// RFC 3164 timestamps have no year; the current one is assumed.
+		if t, err := time.Parse(time.Stamp, m[2]); err == nil {
+			e.Time = t.AddDate(time.Now().Year(), 0, 0)
+		}
+		return e, true
+	}
+	return Entry{}, false
+}
+
+// syslogLevel names the severity of a PRI value.
+func syslogLevel(pri string) (string, bool) {
+	n, err := strconv.Atoi(pri)
+	if err != nil || n > 191 {
+		return "", false
+	}
+	return syslogSeverities[n%8], true
+}
+
+// nilValue turns the RFC 5424 NILVALUE "-" into an empty string.
+func nilValue(s string) string {
+	if s == "-" {
+		return ""
+	}
+	return s
+}
+
+// accessParser reads Apache and Nginx access logs in the common or combined layout.
+// The level follows the status code: 5xx is ERROR, 4xx WARNING and anything else INFO.
+type accessParser struct{}
+
+var accessLine = regexp.MustCompile(`^(\S+) \S+ \S+ \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (?:\d+|-)(?: "(?:[^"\\]|\\.)*" "(?:[^"\\]|\\.)*")?`)
+
+func (accessParser) Name() string { return "combined" }
+
+func (accessParser) Parse(line string) (Entry, bool) {
+	m := accessLine.FindStringSubmatch(line)
+	if m == nil {
+		return Entry{}, false
+	}
+
+	e := Entry{Level: "INFO", Source: m[1], Message: m[3] + " " + m[4]}
+	switch m[4][0] {
+	case '5':
+		e.Level = "ERROR"
+	case '4':
+		e.Level = "WARNING"
+	}
+	e.Time, _ = time.Parse("02/Jan/2006:15:04:05 -0700", m[2])
+	return e, true
+}
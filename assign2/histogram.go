@@ -0,0 +1,204 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// bucketWidths are the values accepted by --histogram.
+var bucketWidths = map[string]time.Duration{
+	"minute": time.Minute,
+	"hour":   time.Hour,
+	"day":    24 * time.Hour,
+}
+
+// maxHistogramBuckets stops a stray timestamp years away from filling the terminal with empty buckets.
+const maxHistogramBuckets = 100000
+
+// sparkTicks are the bar heights of a sparkline, lowest first.
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// histogram counts lines by level in fixed-width time buckets, aligned to UTC.
+type histogram struct {
+	width  time.Duration
+	counts map[time.Time]map[string]int
+}
+
+func newHistogram(width time.Duration) *histogram {
+	return &histogram{width: width, counts: map[time.Time]map[string]int{}}
+}
+
+// add counts one line at t.
+func (h *histogram) add(t time.Time, level string) {
+	start := t.UTC().Truncate(h.width)
+	if h.counts[start] == nil {
+		h.counts[start] = map[string]int{}
+	}
+	h.counts[start][level]++
+}
+
+// merge adds the counts of o, which must have the same bucket width.
+func (h *histogram) merge(o *histogram) {
+	for start, counts := range o.counts {
+		if h.counts[start] == nil {
+			h.counts[start] = map[string]int{}
+		}
+		for level, n := range counts {
+			h.counts[start][level] += n
+		}
+	}
+}
+
+// buckets returns the start of every bucket from the first to the last counted one, empty ones included.
+func (h *histogram) buckets() ([]time.Time, error) {
+	var first, last time.Time
+	for start := range h.counts {
+		if first.IsZero() || start.Before(first) {
+			first = start
+		}
+		if start.After(last) {
+			last = start
+		}
+	}
+	if first.IsZero() {
+		return nil, nil
+	}
+
+	if n := last.Sub(first)/h.width + 1; n > maxHistogramBuckets {
+		return nil, fmt.Errorf("histogram would have %d buckets, narrow it with --since and --until or use a wider bucket", n)
+	}
+	var starts []time.Time
+	for t := first; !t.After(last); t = t.Add(h.width) {
+		starts = append(starts, t)
+	}
+	return starts, nil
+}
+
+// histogramReport is a histogram laid out for printing, one row per bucket from the first
+// to the last counted one.
+type histogramReport struct {
+	Bucket  string        `json:"bucket"`
+	Levels  []string      `json:"levels"` // levels with any count, least severe first
+	Buckets []bucketCount `json:"buckets"`
+	Error   string        `json:"error,omitempty"`
+}
+
+// bucketCount is one row of a histogram.
+type bucketCount struct {
+	Start  time.Time      `json:"start"`
+	Total  int            `json:"total"`
+	Levels map[string]int `json:"levels"`
+}
+
+// report lays the histogram out in rows, empty buckets included.
+func (h *histogram) report(levels *levelTable) (histogramReport, error) {
+	r := histogramReport{Bucket: h.width.String()}
+	for name, width := range bucketWidths {
+		if width == h.width {
+			r.Bucket = name
+		}
+	}
+
+	starts, err := h.buckets()
+	if err != nil {
+		return r, err
+	}
+
+	for _, name := range append(append([]string{}, levels.names...), unclassified) {
+		for _, counts := range h.counts {
+			if counts[name] > 0 {
+				r.Levels = append(r.Levels, name)
+				break
+			}
+		}
+	}
+
+	for _, start := range starts {
+		row := bucketCount{Start: start, Levels: map[string]int{}}
+		for _, name := range r.Levels {
+			row.Levels[name] = h.counts[start][name]
+			row.Total += h.counts[start][name]
+		}
+		r.Buckets = append(r.Buckets, row)
+	}
+	return r, nil
+}
+
+// write prints a row per bucket with its total and the count of every level seen,
+// followed by a sparkline of the totals and one per level.
+func (h *histogram) write(w io.Writer, levels *levelTable) error {
+	r, err := h.report(levels)
+	if err != nil {
+		return err
+	}
+	r.write(w)
+	return nil
+}
+
+func (r histogramReport) write(w io.Writer) {
+	if r.Error != "" {
+		fmt.Fprintln(w, r.Error)
+		return
+	}
+	if len(r.Buckets) == 0 {
+		fmt.Fprintln(w, "No timestamped lines to chart")
+		return
+	}
+
+	fmt.Fprintf(w, "Lines per %s:\n", r.Bucket)
+	layout := "2006-01-02 15:04"
+	if r.Bucket == "day" {
+		layout = time.DateOnly
+	}
+
+	totals := make([]int, len(r.Buckets))
+	perLevel := make(map[string][]int, len(r.Levels))
+	for i, b := range r.Buckets {
+		totals[i] = b.Total
+		row := []string{b.Start.Format(layout), fmt.Sprintf("total %d", b.Total)}
+		for _, name := range r.Levels {
+			perLevel[name] = append(perLevel[name], b.Levels[name])
+			row = append(row, fmt.Sprintf("%s %d", name, b.Levels[name]))
+		}
+		fmt.Fprintln(w, strings.Join(row, "  "))
+	}
+
+	fmt.Fprintf(w, "\n%-12s %s\n", "all", sparkline(totals))
+	for _, name := range r.Levels {
+		fmt.Fprintf(w, "%-12s %s\n", name, sparkline(perLevel[name]))
+	}
+}
+
+// sparkline draws values as a row of bars scaled to the largest value. Zero is always the lowest bar.
+func sparkline(values []int) string {
+	peak := 0
+	for _, v := range values {
+		peak = max(peak, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		tick := 0
+		if peak > 0 {
+			tick = v * (len(sparkTicks) - 1) / peak
+		}
+		b.WriteRune(sparkTicks[tick])
+	}
+	return b.String()
+}
+
+// parseTimeBound reads a --since or --until value: a timestamp, a date, or a duration counted back from now.
+func parseTimeBound(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	if t, ok := parseTimestamp(s); ok {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, use a timestamp such as 2024-06-12T10:00:00Z, a date or a duration such as 2h", s)
+}
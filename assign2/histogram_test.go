@@ -0,0 +1,103 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistogram(t *testing.T) {
+	log := strings.Join([]string{
+		"2024-06-12T10:00:05Z [INFO] start",
+		"2024-06-12T10:00:30Z [INFO] ready",
+		"2024-06-12T10:02:01Z [ERROR] boom",
+		"2024-06-12T10:02:11Z [ERROR] boom",
+		"2024-06-12T10:02:21Z [ERR] boom",
+		"2024-06-12T11:00:00Z [WARN] slow",
+		"no timestamp",
+	}, "\n")
+
+	tests := []struct {
+		name        string
+		cfg         config
+		wantLines   int
+		wantOutside int
+		wantRows    []string
+		wantSpark   string
+	}{
+		{
+			name:      "Per_Hour",
+			cfg:       config{Bucket: time.Hour},
+			wantLines: 7,
+			wantRows:  []string{"2024-06-12 10:00  total 5  INFO 2  WARNING 0  ERROR 3", "2024-06-12 11:00  total 1  INFO 0  WARNING 1  ERROR 0"},
+			wantSpark: "all          █▂",
+		},
+		{
+			name:        "Per_Minute_In_Window",
+			cfg:         config{Bucket: time.Minute, Since: time.Date(2024, 6, 12, 10, 0, 10, 0, time.UTC), Until: time.Date(2024, 6, 12, 11, 0, 0, 0, time.UTC)},
+			wantLines:   4,
+			wantOutside: 3,
+			wantRows:    []string{"2024-06-12 10:00  total 1  INFO 1  ERROR 0", "2024-06-12 10:01  total 0  INFO 0  ERROR 0", "2024-06-12 10:02  total 3  INFO 0  ERROR 3"},
+			wantSpark:   "ERROR        ▁▁█",
+		},
+	}
+
+	for _, tt := range tests {
+		tt.cfg.Parser, tt.cfg.Levels = bracketParser{}, defaultLevelTable()
+		result, err := analyze(strings.NewReader(log), tt.cfg)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if result.Lines != tt.wantLines || result.OutsideWindow != tt.wantOutside {
+			t.Errorf("%s: expected %d lines and %d outside the window, got %d and %d", tt.name, tt.wantLines, tt.wantOutside, result.Lines, result.OutsideWindow)
+		}
+
+		var out strings.Builder
+		if err := result.Histogram.write(&out, tt.cfg.Levels); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		for _, want := range append(tt.wantRows, tt.wantSpark) {
+			if !strings.Contains(out.String(), want+"\n") {
+				t.Errorf("%s: expected the histogram to contain %q, got:\n%s", tt.name, want, out.String())
+			}
+		}
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		values []int
+		want   string
+	}{
+		{[]int{0, 1, 2, 3, 4, 5, 6, 7}, "▁▂▃▄▅▆▇█"},
+		{[]int{0, 0}, "▁▁"},
+		{[]int{5, 10}, "▄█"},
+	}
+
+	for _, tt := range tests {
+		if got := sparkline(tt.values); got != tt.want {
+			t.Errorf("sparkline(%v) = %s, want %s", tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	now := time.Date(2024, 6, 12, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in      string
+		want    time.Time
+		wantErr bool
+	}{
+		{in: "2h", want: now.Add(-2 * time.Hour)},
+		{in: "2024-06-01", want: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{in: "2024-06-12T10:30:00+02:00", want: time.Date(2024, 6, 12, 8, 30, 0, 0, time.UTC)},
+		{in: "yesterday", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimeBound(tt.in, now)
+		if (err != nil) != tt.wantErr || !got.Equal(tt.want) {
+			t.Errorf("parseTimeBound(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
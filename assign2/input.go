@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// stdinPath is the path that reads the log from standard input.
+const stdinPath = "-"
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// expandInputs turns the paths on the command line into the list of logs to read. Globs are
+// expanded in sorted order, and a glob that matches nothing is an error rather than a silent skip.
+func expandInputs(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		if arg == stdinPath || !strings.ContainsAny(arg, "*?[") {
+			paths = append(paths, arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no files match %s", arg)
+		}
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+// openInput opens a log for reading, with "-" meaning standard input. Gzip and zstd
+// streams are recognised by their magic numbers and decompressed on the fly.
+func openInput(path string) (io.ReadCloser, error) {
+	var file io.ReadCloser = io.NopCloser(os.Stdin)
+	if path != stdinPath {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		file = f
+	}
+
+	buffered := bufio.NewReader(file)
+	magic, _ := buffered.Peek(len(zstdMagic))
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return decompressor{Reader: gz, closers: []func() error{gz.Close, file.Close}}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(buffered)
+		if err != nil {
+			file.Close()
+			return nil, err
+		}
+		return decompressor{Reader: zr, closers: []func() error{func() error { zr.Close(); return nil }, file.Close}}, nil
+	default:
+		return decompressor{Reader: buffered, closers: []func() error{file.Close}}, nil
+	}
+}
+
+// decompressor reads through a decompressing reader and closes it along with the file beneath.
+type decompressor struct {
+	io.Reader
+	closers []func() error
+}
+
+func (d decompressor) Close() error {
+	var first error
+	for _, closeFn := range d.closers {
+		if err := closeFn(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// fileAnalysis is the analysis of one of several inputs.
+type fileAnalysis struct {
+	Path string
+	analysis
+}
+
+// analyzeInputs reads each log in turn, detecting its layout separately, and returns the
+// analysis of every file together with their combined totals.
+func analyzeInputs(paths []string, cfg config) ([]fileAnalysis, analysis, error) {
+	combined := analysis{Levels: map[string]int{}, Signatures: signatureSet{}, Recent: cfg.Rules.newTimeline()}
+	if cfg.Bucket > 0 {
+		combined.Histogram = newHistogram(cfg.Bucket)
+	}
+
+	var files []fileAnalysis
+	for _, path := range paths {
+		in, err := openInput(path)
+		if err != nil {
+			return nil, analysis{}, err
+		}
+		if cfg.Print != nil {
+			cfg.Print.start(path, len(paths) > 1)
+		}
+		result, err := analyze(in, cfg)
+		in.Close()
+		if err != nil {
+			return nil, analysis{}, fmt.Errorf("reading %s: %w", path, err)
+		}
+
+		files = append(files, fileAnalysis{Path: path, analysis: result})
+		if len(paths) > 1 {
+			result.Signatures.shift(0, path)
+		}
+		combined.merge(result)
+	}
+	return files, combined, nil
+}
+
+// merge adds the counts of o to a. Formats that differ are listed together.
+func (a *analysis) merge(o analysis) {
+	switch {
+	case a.Format == "":
+		a.Format = o.Format
+	case !strings.Contains(","+a.Format+",", ","+o.Format+","):
+		a.Format += "," + o.Format
+	}
+
+	a.Lines += o.Lines
+	a.Read += o.Read
+	a.OutsideWindow += o.OutsideWindow
+	a.FilteredOut += o.FilteredOut
+	for level, n := range o.Levels {
+		a.Levels[level] += n
+	}
+	a.Signatures.merge(o.Signatures)
+	if a.Recent != nil && o.Recent != nil {
+		a.Recent.merge(o.Recent)
+	}
+	if a.Histogram != nil && o.Histogram != nil {
+		a.Histogram.merge(o.Histogram)
+	}
+}
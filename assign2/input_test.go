@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestAnalyzeInputs(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, data []byte) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("app.log", []byte("[INFO] a\n[ERROR] b\n"))
+	write("app.log.1", []byte(`{"level":"warn","msg":"c"}`+"\n"))
+
+	var gz bytes.Buffer
+	gzw := gzip.NewWriter(&gz)
+	gzw.Write([]byte("[ERROR] d\n[ERROR] e\n"))
+	gzw.Close()
+	write("app.log.2.gz", gz.Bytes())
+
+	zw, err := zstd.NewWriter(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write("app.log.3.zst", zw.EncodeAll([]byte("[DEBUG] f\n"), nil))
+	zw.Close()
+
+	paths, err := expandInputs([]string{filepath.Join(dir, "app.log*")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 4 {
+		t.Fatalf("Expected the glob to match 4 files, got %v", paths)
+	}
+
+	files, combined, err := analyzeInputs(paths, config{Levels: defaultLevelTable()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		format string
+		levels map[string]int
+	}{
+		{"bracket", map[string]int{"INFO": 1, "ERROR": 1}},
+		{"json", map[string]int{"WARNING": 1}},
+		{"bracket", map[string]int{"ERROR": 2}},
+		{"bracket", map[string]int{"DEBUG": 1}},
+	}
+	for i, f := range files {
+		if f.Format != want[i].format || len(f.Levels) != len(want[i].levels) {
+			t.Errorf("%s: expected %s %v, got %s %v", f.Path, want[i].format, want[i].levels, f.Format, f.Levels)
+			continue
+		}
+		for level, n := range want[i].levels {
+			if f.Levels[level] != n {
+				t.Errorf("%s: expected %d %s lines, got %d", f.Path, n, level, f.Levels[level])
+			}
+		}
+	}
+
+	if combined.Lines != 6 || combined.Levels["ERROR"] != 3 || combined.Format != "bracket,json" {
+		t.Errorf("Unexpected combined totals: %+v", combined)
+	}
+
+	if _, err := expandInputs([]string{filepath.Join(dir, "*.missing")}); err == nil {
+		t.Errorf("Expected an error for a glob that matches nothing")
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// unclassified is the bucket for lines without a level the table knows, blank lines included.
+const unclassified = "UNCLASSIFIED"
+
+// levelDef is one entry of a level table file.
+type levelDef struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// defaultLevels is the built-in level table, least severe first.
+var defaultLevels = []levelDef{
+	{Name: "TRACE"},
+	{Name: "DEBUG", Aliases: []string{"DBG"}},
+	{Name: "INFO", Aliases: []string{"INFORMATION"}},
+	{Name: "NOTICE"},
+	{Name: "WARNING", Aliases: []string{"WARN"}},
+	{Name: "ERROR", Aliases: []string{"ERR"}},
+	{Name: "CRITICAL", Aliases: []string{"CRIT", "ALERT"}},
+	{Name: "FATAL", Aliases: []string{"EMERG", "EMERGENCY", "PANIC"}},
+}
+
+// levelTable maps the level names and aliases found in logs to canonical levels, which it keeps in severity order.
+type levelTable struct {
+	names    []string       // canonical names, least severe first
+	severity map[string]int // upper-case name or alias to the index of its level in names
+}
+
+// newLevelTable builds a table from definitions listed least severe first.
+func newLevelTable(defs []levelDef) (*levelTable, error) {
+	if len(defs) == 0 {
+		return nil, fmt.Errorf("level table is empty")
+	}
+
+	t := &levelTable{severity: map[string]int{}}
+	for i, def := range defs {
+		name := strings.ToUpper(strings.TrimSpace(def.Name))
+		if name == "" {
+			return nil, fmt.Errorf("level %d has no name", i+1)
+		}
+		t.names = append(t.names, name)
+
+		for _, key := range append([]string{name}, def.Aliases...) {
+			key = strings.ToUpper(strings.TrimSpace(key))
+			if key == unclassified {
+				return nil, fmt.Errorf("%s is reserved for lines without a known level", unclassified)
+			}
+			if _, dup := t.severity[key]; dup {
+				return nil, fmt.Errorf("level %s is defined twice", key)
+			}
+			t.severity[key] = i
+		}
+	}
+	return t, nil
+}
+
+// defaultLevelTable returns the built-in table.
+func defaultLevelTable() *levelTable {
+	t, err := newLevelTable(defaultLevels)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// loadLevelTable reads a table from a JSON file such as
+// [{"name":"DEBUG"},{"name":"INFO"},{"name":"WARNING","aliases":["WARN"]}], least severe first.
+func loadLevelTable(path string) (*levelTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading level table: %w", err)
+	}
+
+	var defs []levelDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return nil, fmt.Errorf("reading level table %s: %w", path, err)
+	}
+	return newLevelTable(defs)
+}
+
+// classify returns the canonical level for a level as written in a log, or unclassified.
+func (t *levelTable) classify(raw string) string {
+	if i, ok := t.severity[strings.ToUpper(strings.TrimSpace(raw))]; ok {
+		return t.names[i]
+	}
+	return unclassified
+}
+
+// rank orders canonical levels by severity; unclassified ranks below every level.
+func (t *levelTable) rank(level string) int {
+	if i, ok := t.severity[level]; ok {
+		return i
+	}
+	return -1
+}
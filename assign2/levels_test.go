@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevelTable(t *testing.T) {
+	levels := defaultLevelTable()
+
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{"WARN", "WARNING"},
+		{"err", "ERROR"},
+		{"Crit", "CRITICAL"},
+		{"EMERG", "FATAL"},
+		{"TRACE", "TRACE"},
+		{"VERBOSE", unclassified},
+		{"", unclassified},
+	}
+	for _, tt := range tests {
+		if got := levels.classify(tt.raw); got != tt.want {
+			t.Errorf("classify(%q) = %s, want %s", tt.raw, got, tt.want)
+		}
+	}
+
+	if !(levels.rank("DEBUG") < levels.rank("INFO") && levels.rank("ERROR") < levels.rank("FATAL")) {
+		t.Errorf("Expected levels to rank by severity")
+	}
+	if levels.rank(unclassified) >= levels.rank("TRACE") {
+		t.Errorf("Expected unclassified lines to rank below every level")
+	}
+
+	if _, err := newLevelTable([]levelDef{{Name: "INFO"}, {Name: "NOTE", Aliases: []string{"info"}}}); err == nil {
+		t.Errorf("Expected an error for an alias that repeats a level")
+	}
+}
+
+func TestAnalyzeAccountsForEveryLine(t *testing.T) {
+	log := "[DEBUG] a\n[WARN] b\n[ERR] c\n[FATAL] d\nplain text\n\n[VERBOSE] e\n"
+	custom, err := newLevelTable([]levelDef{{Name: "LOW", Aliases: []string{"DEBUG", "VERBOSE"}}, {Name: "HIGH", Aliases: []string{"ERR", "FATAL"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		levels *levelTable
+		want   map[string]int
+	}{
+		{"Default_Table", defaultLevelTable(), map[string]int{"DEBUG": 1, "WARNING": 1, "ERROR": 1, "FATAL": 1, unclassified: 3}},
+		{"Custom_Table", custom, map[string]int{"LOW": 2, "HIGH": 2, unclassified: 3}},
+	}
+
+	for _, tt := range tests {
+		result, err := analyze(strings.NewReader(log), config{Parser: bracketParser{}, Levels: tt.levels})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+
+		sum := 0
+		for level, n := range result.Levels {
+			sum += n
+			if n != tt.want[level] {
+				t.Errorf("%s: expected %d %s lines, got %d", tt.name, tt.want[level], level, n)
+			}
+		}
+		if sum != result.Lines {
+			t.Errorf("%s: level counts add up to %d, expected %d lines", tt.name, sum, result.Lines)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry holds the fields parsed from one log line. Fields a layout does not carry are left empty.
+type Entry struct {
+	Time    time.Time // zero when the line has no timestamp
+	Level   string    // upper case, as written in the line
+	Source  string    // program, component or client that wrote the line
+	Message string
+	Fields  map[string]string // every key/value pair of a structured line, keys in lower case
+}
+
+// field returns the value of a key of a structured line. The level, source and message of any
+// layout can be asked for as "level", "source" and "msg" or "message" too.
+func (e Entry) field(key string) (string, bool) {
+	if v, ok := e.Fields[key]; ok {
+		return v, true
+	}
+	switch key {
+	case "level":
+		return e.Level, e.Level != ""
+	case "source":
+		return e.Source, e.Source != ""
+	case "msg", "message":
+		return e.Message, true
+	}
+	return "", false
+}
+
+// LineParser reads the lines of one log layout.
+type LineParser interface {
+	// Name is the value of --format that selects the parser.
+	Name() string
+	// Parse extracts the fields of line, reporting false when the line is not in this layout.
+	Parse(line string) (Entry, bool)
+}
+
+// parsers lists the built-in layouts, most specific first, which is also the order
+// auto-detection prefers when two layouts read the same number of sample lines.
+var parsers = []LineParser{
+	jsonParser{},
+	syslogParser{},
+	accessParser{},
+	logfmtParser{},
+	bracketParser{},
+}
+
+// detectSampleLines is how many non-empty lines auto-detection looks at.
+const detectSampleLines = 50
+
+// lookupParser finds the parser for a --format value. "auto" returns nil, asking for detection.
+func lookupParser(format string) (LineParser, error) {
+	if format == "" || format == "auto" {
+		return nil, nil
+	}
+
+	names := []string{"auto"}
+	for _, p := range parsers {
+		if p.Name() == format {
+			return p, nil
+		}
+		names = append(names, p.Name())
+	}
+	return nil, fmt.Errorf("unknown format %q, use one of %s", format, strings.Join(names, ", "))
+}
+
+// detectParser picks the layout that reads the most sample lines. Logs no layout
+// can read fall back to the bracket layout, leaving every line unclassified.
+func detectParser(sample []string) LineParser {
+	best, bestScore := LineParser(bracketParser{}), 0
+	for _, p := range parsers {
+		score := 0
+		for _, line := range sample {
+			if _, ok := p.Parse(line); ok {
+				score++
+			}
+		}
+		if score > bestScore {
+			best, bestScore = p, score
+		}
+	}
+	return best
+}
+
+// timestampLayouts are the timestamp formats recognised in structured fields.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05Z07:00",
+	"2006/01/02 15:04:05",
+}
+
+// parseTimestamp reads a timestamp in one of the usual layouts or as Unix seconds or milliseconds.
+func parseTimestamp(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	for _, layout := range timestampLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f > 0 {
+		// Anything past the year 33658 in seconds is taken to be milliseconds.
+		if f > 1e12 {
+			f /= 1000
+		}
+		sec := int64(f)
+		return time.Unix(sec, int64((f-float64(sec))*1e9)).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// Field names used by structured layouts, in order of preference.
+var (
+	timeKeys    = []string{"time", "ts", "timestamp", "@timestamp", "t"}
+	levelKeys   = []string{"level", "lvl", "severity", "loglevel"}
+	sourceKeys  = []string{"source", "logger", "component", "service", "app", "caller"}
+	messageKeys = []string{"msg", "message"}
+)
+
+// entryFromFields builds an Entry from the key/value pairs of a structured line.
+// It reports false when the pairs carry neither a level nor a message.
+func entryFromFields(fields map[string]string) (Entry, bool) {
+	lookup := func(keys []string) (string, bool) {
+		for _, k := range keys {
+			if v, ok := fields[k]; ok {
+				return v, true
+			}
+		}
+		return "", false
+	}
+
+	e := Entry{Fields: fields}
+	level, hasLevel := lookup(levelKeys)
+	message, hasMessage := lookup(messageKeys)
+	if !hasLevel && !hasMessage {
+		return Entry{}, false
+	}
+	e.Level, e.Message = strings.ToUpper(level), message
+	e.Source, _ = lookup(sourceKeys)
+	if ts, ok := lookup(timeKeys); ok {
+		e.Time, _ = parseTimestamp(ts)
+	}
+	return e, true
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsers(t *testing.T) {
+	year := time.Now().Year()
+	tests := []struct {
+		name   string
+		parser LineParser
+		line   string
+		want   Entry
+		wantOK bool
+	}{
+		{
+			name:   "Bracket",
+			parser: bracketParser{},
+			line:   "[INFO] retrying after [ERROR]",
+			want:   Entry{Level: "INFO", Message: "retrying after [ERROR]"},
+			wantOK: true,
+		},
+		{
+			name:   "Bracket_Timestamp_Source",
+			parser: bracketParser{},
+			line:   "2024-06-12 10:00:01 [warning] [db] pool exhausted",
+			want:   Entry{Time: time.Date(2024, 6, 12, 10, 0, 1, 0, time.UTC), Level: "WARNING", Source: "db", Message: "pool exhausted"},
+			wantOK: true,
+		},
+		{
+			name:   "Bracket_Level_Not_First",
+			parser: bracketParser{},
+			line:   "retrying after [ERROR]",
+		},
+		{
+			name:   "Logfmt",
+			parser: logfmtParser{},
+			line:   `time=2024-06-12T10:00:00Z level=error component=api msg="upstream \"auth\" timed out"`,
+			want:   Entry{Time: time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC), Level: "ERROR", Source: "api", Message: `upstream "auth" timed out`},
+			wantOK: true,
+		},
+		{
+			name:   "Logfmt_Plain_Text",
+			parser: logfmtParser{},
+			line:   "[INFO] a=b c=d",
+		},
+		{
+			name:   "JSON",
+			parser: jsonParser{},
+			line:   `{"ts":1718186400,"Level":"warn","logger":"cache","message":"evicting"}`,
+			want:   Entry{Time: time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC), Level: "WARN", Source: "cache", Message: "evicting"},
+			wantOK: true,
+		},
+		{
+			name:   "JSON_Without_Level_Or_Message",
+			parser: jsonParser{},
+			line:   `{"status":200}`,
+		},
+		{
+			name:   "Syslog_5424",
+			parser: syslogParser{},
+			line:   `<165>1 2024-06-12T10:00:00.003Z host01 sshd 4123 ID47 [exampleSDID@32473 iut="3"] Accepted key`,
+			want:   Entry{Time: time.Date(2024, 6, 12, 10, 0, 0, 3000000, time.UTC), Level: "NOTICE", Source: "sshd", Message: "Accepted key"},
+			wantOK: true,
+		},
+		{
+			name:   "Syslog_3164",
+			parser: syslogParser{},
+			line:   "<11>Jun  2 10:00:00 host01 cron[991]: job failed",
+			want:   Entry{Time: time.Date(year, 6, 2, 10, 0, 0, 0, time.UTC), Level: "ERR", Source: "cron", Message: "job failed"},
+			wantOK: true,
+		},
+		{
+			name:   "Syslog_3164_Without_PRI",
+			parser: syslogParser{},
+			line:   "Jun 12 10:00:00 host01 kernel: eth0 up",
+			want:   Entry{Time: time.Date(year, 6, 12, 10, 0, 0, 0, time.UTC), Source: "kernel", Message: "eth0 up"},
+			wantOK: true,
+		},
+		{
+			name:   "Combined",
+			parser: accessParser{},
+			line:   `203.0.113.7 - frank [12/Jun/2024:10:00:00 +0000] "GET /health HTTP/1.1" 503 12 "-" "curl/8.0"`,
+			want:   Entry{Time: time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC), Level: "ERROR", Source: "203.0.113.7", Message: "GET /health HTTP/1.1 503"},
+			wantOK: true,
+		},
+		{
+			name:   "Common",
+			parser: accessParser{},
+			line:   `::1 - - [12/Jun/2024:10:00:00 +0000] "POST /login HTTP/1.1" 404 -`,
+			want:   Entry{Time: time.Date(2024, 6, 12, 10, 0, 0, 0, time.UTC), Level: "WARNING", Source: "::1", Message: "POST /login HTTP/1.1 404"},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		got, ok := tt.parser.Parse(tt.line)
+		if ok != tt.wantOK {
+			t.Errorf("%s: expected ok=%v, got %v", tt.name, tt.wantOK, ok)
+			continue
+		}
+		if !got.Time.Equal(tt.want.Time) || got.Level != tt.want.Level || got.Source != tt.want.Source || got.Message != tt.want.Message {
+			t.Errorf("%s: expected %+v, got %+v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestAnalyzeDetectsFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		log        string
+		wantFormat string
+		wantLevels map[string]int
+	}{
+		{
+			name:       "Bracket",
+			log:        "[INFO] retrying after [ERROR]\n[ERROR] Timeout occurred\n\nnot a log line\n",
+			wantFormat: "bracket",
+			wantLevels: map[string]int{"INFO": 1, "ERROR": 1},
+		},
+		{
+			name:       "JSON",
+			log:        `{"level":"info","msg":"a"}` + "\n" + `{"level":"error","msg":"b"}` + "\n",
+			wantFormat: "json",
+			wantLevels: map[string]int{"INFO": 1, "ERROR": 1},
+		},
+		{
+			name:       "Logfmt",
+			log:        "level=info msg=a\nlevel=warning msg=b\n",
+			wantFormat: "logfmt",
+			wantLevels: map[string]int{"INFO": 1, "WARNING": 1},
+		},
+		{
+			name:       "Combined",
+			log:        `1.2.3.4 - - [12/Jun/2024:10:00:00 +0000] "GET / HTTP/1.1" 200 5 "-" "x"` + "\n",
+			wantFormat: "combined",
+			wantLevels: map[string]int{"INFO": 1},
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := analyze(strings.NewReader(tt.log), config{Levels: defaultLevelTable()})
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got.Format != tt.wantFormat {
+			t.Errorf("%s: expected format %s, got %s", tt.name, tt.wantFormat, got.Format)
+		}
+		if got.Lines != strings.Count(tt.log, "\n") {
+			t.Errorf("%s: expected %d lines, got %d", tt.name, strings.Count(tt.log, "\n"), got.Lines)
+		}
+		for level, want := range tt.wantLevels {
+			if got.Levels[level] != want {
+				t.Errorf("%s: expected %d %s lines, got %d", tt.name, want, level, got.Levels[level])
+			}
+		}
+	}
+
+	if _, err := lookupParser("xml"); err == nil {
+		t.Errorf("Expected an error for an unknown format")
+	}
+}
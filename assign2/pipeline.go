@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+)
+
+// pipelineChunkSize is how much of a log each parser worker is handed at a time.
+const pipelineChunkSize = 1 << 20
+
+// chunkReader cuts a stream into chunks that end on line boundaries.
+type chunkReader struct {
+	r     io.Reader
+	size  int
+	carry []byte // start of a line that did not fit in the previous chunk
+	eof   bool
+}
+
+// next returns the next chunk: whole lines with their newlines, except that the last chunk
+// of the stream may end without one. A line longer than the chunk size makes a longer chunk.
+func (cr *chunkReader) next() ([]byte, error) {
+	buf := cr.carry
+	cr.carry = nil
+	for !cr.eof {
+		start := len(buf)
+		buf = append(buf, make([]byte, cr.size)...)
+		n, err := io.ReadFull(cr.r, buf[start:])
+		buf = buf[:start+n]
+
+		switch {
+		case errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+			cr.eof = true
+		case err != nil:
+			return nil, err
+		}
+
+		if end := bytes.LastIndexByte(buf, '\n'); end >= 0 && !cr.eof {
+			cr.carry = append([]byte(nil), buf[end+1:]...)
+			return buf[:end+1], nil
+		}
+	}
+
+	if len(buf) == 0 {
+		return nil, io.EOF
+	}
+	return buf, nil
+}
+
+// eachLine calls fn for every line of a chunk, splitting them the way bufio.ScanLines does.
+func eachLine(chunk []byte, fn func(string)) {
+	for len(chunk) > 0 {
+		line := chunk
+		if i := bytes.IndexByte(chunk, '\n'); i >= 0 {
+			line, chunk = chunk[:i], chunk[i+1:]
+		} else {
+			chunk = nil
+		}
+		fn(string(bytes.TrimSuffix(line, []byte{'\r'})))
+	}
+}
+
+// chunkJob is a chunk on its way through the pipeline, numbered so results merge in order.
+type chunkJob struct {
+	index  int
+	data   []byte
+	result analysis
+}
+
+// analyzeParallel gives the same analysis as reading r line by line, using workers goroutines
+// to parse chunks of chunkSize bytes. The layout is detected from the first lines before any
+// chunk is handed out, so every worker uses the parser the sequential path would have chosen.
+func analyzeParallel(r io.Reader, cfg config, workers, chunkSize int) (analysis, error) {
+	reader := &chunkReader{r: r, size: chunkSize}
+
+	// Hold back enough chunks to detect the layout from.
+	var early [][]byte
+	if cfg.Parser == nil {
+		var sample []string
+		for len(sample) < detectSampleLines {
+			chunk, err := reader.next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return analysis{}, err
+			}
+			early = append(early, chunk)
+			eachLine(chunk, func(line string) {
+				if len(sample) < detectSampleLines && strings.TrimSpace(line) != "" {
+					sample = append(sample, line)
+				}
+			})
+		}
+		cfg.Parser = detectParser(sample)
+	}
+
+	jobs := make(chan chunkJob, workers)
+	results := make(chan chunkJob, workers)
+	var readErr error
+	go func() {
+		defer close(jobs)
+		index := 0
+		for _, chunk := range early {
+			jobs <- chunkJob{index: index, data: chunk}
+			index++
+		}
+		for {
+			chunk, err := reader.next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr = err
+				}
+				return
+			}
+			jobs <- chunkJob{index: index, data: chunk}
+			index++
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				c := newCounter(cfg)
+				eachLine(job.data, c.add)
+				c.close()
+				job.result, job.data = c.analysis(), nil
+				results <- job
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// Merge in chunk order, so anything that depends on line order sees the file as written.
+	merged := newCounter(cfg).analysis()
+	pending := map[int]analysis{}
+	next := 0
+	for job := range results {
+		pending[job.index] = job.result
+		for result, ok := pending[next]; ok; result, ok = pending[next] {
+			// Line numbers in a chunk count from its start.
+			result.Signatures.shift(merged.Read, "")
+			merged.merge(result)
+			delete(pending, next)
+			next++
+		}
+	}
+	return merged, readErr
+}
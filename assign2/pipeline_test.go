@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// syntheticLog builds a log of n lines in the bracket layout with timestamps, blank lines,
+// Windows line endings and lines no parser reads, so chunk boundaries fall everywhere.
+func syntheticLog(n int, seed int64) []byte {
+	rng := rand.New(rand.NewSource(seed))
+	levels := []string{"DEBUG", "INFO", "WARN", "ERROR", "ERR", "FATAL", "TRACE", "BOGUS"}
+	start := time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC)
+
+	var b bytes.Buffer
+	for i := 0; i < n; i++ {
+		switch rng.Intn(20) {
+		case 0:
+			b.WriteString("\n")
+		case 1:
+			b.WriteString("continuation of the previous line\r\n")
+		default:
+			at := start.Add(time.Duration(i) * 1500 * time.Millisecond).Format(time.RFC3339)
+			fmt.Fprintf(&b, "%s [%s] request %d took %dms\n", at, levels[rng.Intn(len(levels))], i, rng.Intn(5000))
+		}
+	}
+	return b.Bytes()
+}
+
+func TestAnalyzeParallelMatchesSequential(t *testing.T) {
+	logs := map[string][]byte{
+		"Synthetic":       syntheticLog(5000, 1),
+		"Small_Synthetic": syntheticLog(100, 2),
+		"No_Final_Line":   []byte("[INFO] a\n[ERROR] b"),
+		"Empty":           nil,
+		"Long_Line":       []byte("[INFO] " + strings.Repeat("x", 70*1024) + "\n[WARN] short\n"),
+		"JSON_After_Text": append([]byte("not a log line\n"), []byte(`{"level":"error","msg":"x"}`+"\n")...),
+	}
+
+	for name, data := range logs {
+		for _, cfg := range []config{
+			{Levels: defaultLevelTable()},
+			{Levels: defaultLevelTable(), Bucket: time.Minute},
+			{Levels: defaultLevelTable(), Parser: bracketParser{}, Since: time.Date(2024, 6, 12, 0, 30, 0, 0, time.UTC)},
+		} {
+			want, err := analyze(bytes.NewReader(data), cfg)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", name, err)
+			}
+
+			for _, workers := range []int{2, 3, 8} {
+				for _, chunkSize := range []int{1, 7, 64, 4096} {
+					if chunkSize < 64 && len(data) > 10000 {
+						continue
+					}
+					got, err := analyzeParallel(bytes.NewReader(data), cfg, workers, chunkSize)
+					if err != nil {
+						t.Fatalf("%s: unexpected error: %v", name, err)
+					}
+					if !reflect.DeepEqual(got, want) {
+						t.Errorf("%s with %d workers and %d-byte chunks: got %+v, want %+v", name, workers, chunkSize, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func BenchmarkAnalyzeSequential(b *testing.B) {
+	data := syntheticLog(200000, 1)
+	cfg := config{Levels: defaultLevelTable()}
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := analyze(bytes.NewReader(data), cfg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkAnalyzeParallel runs one worker per GOMAXPROCS; compare with go test -bench Parallel -cpu 1,2,4,8.
+func BenchmarkAnalyzeParallel(b *testing.B) {
+	data := syntheticLog(200000, 1)
+	cfg := config{Levels: defaultLevelTable(), Workers: runtime.GOMAXPROCS(0)}
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := analyzeParallel(bytes.NewReader(data), cfg, cfg.Workers, pipelineChunkSize); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,442 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// report is everything a run found, in a shape each --output format renders on its own.
+type report struct {
+	Inputs        []string          `json:"inputs"`
+	Format        string            `json:"format"`
+	AnalyzedAt    time.Time         `json:"analyzed_at"`
+	Lines         int               `json:"lines"`      // entries counted, which are lines unless --multiline joins them
+	LinesRead     int               `json:"lines_read"` // physical lines, counted or not
+	OutsideWindow int               `json:"outside_window"`
+	FilteredOut   int               `json:"filtered_out"`
+	Levels        []levelCount      `json:"levels"` // every level least severe first, then unclassified
+	Files         []fileReport      `json:"files,omitempty"`
+	Histogram     *histogramReport  `json:"histogram,omitempty"`
+	Signatures    []signatureReport `json:"signatures,omitempty"`
+	Comparison    *comparison       `json:"comparison,omitempty"` // set by --compare
+}
+
+// levelCount is the number of lines of one level and their share of all lines.
+type levelCount struct {
+	Level      string  `json:"level"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+// fileReport is the totals of one input of a run over several.
+type fileReport struct {
+	Path   string       `json:"path"`
+	Format string       `json:"format"`
+	Lines  int          `json:"lines"`
+	Levels []levelCount `json:"levels"`
+}
+
+// reportWriter renders a report in one output format.
+type reportWriter func(w io.Writer, r report) error
+
+// reportWriters are the values accepted by --output.
+var reportWriters = map[string]reportWriter{
+	"text":       writeTextReport,
+	"json":       writeJSONReport,
+	"csv":        writeCSVReport,
+	"markdown":   writeMarkdownReport,
+	"prometheus": writePrometheusReport,
+}
+
+// newReport gathers the combined result of a run, the per-file results when there are several
+// inputs, and the top signatures of every level.
+func newReport(inputs []string, files []fileAnalysis, result analysis, levels *levelTable, top int, now time.Time) report {
+	r := report{
+		Inputs:        inputs,
+		Format:        result.Format,
+		AnalyzedAt:    now,
+		Lines:         result.Lines,
+		LinesRead:     result.Read,
+		OutsideWindow: result.OutsideWindow,
+		FilteredOut:   result.FilteredOut,
+		Levels:        levelCounts(result, levels),
+	}
+	if len(files) > 1 {
+		for _, f := range files {
+			r.Files = append(r.Files, fileReport{Path: f.Path, Format: f.Format, Lines: f.Lines, Levels: levelCounts(f.analysis, levels)})
+		}
+	}
+	if result.Histogram != nil {
+		h, err := result.Histogram.report(levels)
+		if err != nil {
+			h.Error = err.Error()
+		}
+		r.Histogram = &h
+	}
+	if top > 0 {
+		r.Signatures = result.Signatures.report(levels, top)
+	}
+	return r
+}
+
+func levelCounts(result analysis, levels *levelTable) []levelCount {
+	var counts []levelCount
+	for _, name := range append(append([]string{}, levels.names...), unclassified) {
+		c := levelCount{Level: name, Count: result.Levels[name]}
+		if result.Lines > 0 {
+			c.Percentage = float64(c.Count) / float64(result.Lines) * 100
+		}
+		counts = append(counts, c)
+	}
+	return counts
+}
+
+// writeTextReport prints the report for people: per-file totals when there are several inputs,
+// then the count and share of every level, the histogram and the top signatures.
+func writeTextReport(out io.Writer, r report) error {
+	w := &errWriter{w: out}
+	if len(r.Files) > 0 {
+		width := 0
+		for _, f := range r.Files {
+			width = max(width, len(f.Path))
+		}
+		fmt.Fprintln(w, "Per-file totals:")
+		for _, f := range r.Files {
+			row := []string{fmt.Sprintf("%-*s", width, f.Path), f.Format, fmt.Sprintf("lines %d", f.Lines)}
+			for _, c := range f.Levels {
+				if c.Count > 0 {
+					row = append(row, fmt.Sprintf("%s %d", c.Level, c.Count))
+				}
+			}
+			fmt.Fprintln(w, strings.Join(row, "  "))
+		}
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "Log Analysis of file: %s\n", strings.Join(r.Inputs, ", "))
+	fmt.Fprintf(w, "Format: %s\n\n", r.Format)
+	for _, c := range r.Levels {
+		fmt.Fprintf(w, "%s: %d entries\n", c.Level, c.Count)
+	}
+
+	fmt.Fprintf(w, "\nTotal log lines: %d\n", r.Lines)
+	if r.joined() {
+		fmt.Fprintf(w, "Physical lines read: %d\n", r.LinesRead)
+	}
+	if r.OutsideWindow > 0 {
+		fmt.Fprintf(w, "Lines outside the time window: %d\n", r.OutsideWindow)
+	}
+	if r.FilteredOut > 0 {
+		fmt.Fprintf(w, "Lines filtered out: %d\n", r.FilteredOut)
+	}
+	if r.Lines > 0 {
+		for _, c := range r.Levels {
+			fmt.Fprintf(w, "%s percentage: %.2f%%\n", c.Level, c.Percentage)
+		}
+	}
+
+	if r.Histogram != nil {
+		fmt.Fprintln(w)
+		r.Histogram.write(w)
+	}
+
+	for i, sig := range r.Signatures {
+		if i == 0 || sig.Level != r.Signatures[i-1].Level {
+			fmt.Fprintf(w, "\nTop %s signatures:\n", sig.Level)
+		}
+		fmt.Fprintf(w, "%6d  %s  (first line %s, last line %s)\n", sig.Count, sig.Text, sig.First, sig.Last)
+	}
+
+	if r.Comparison != nil {
+		r.Comparison.write(w)
+	}
+
+	fmt.Fprintf(w, "\nAnalyzed at: %s\n", r.AnalyzedAt.Format(time.DateTime))
+	return w.err
+}
+
+// joined reports whether entries of several lines were counted once.
+func (r report) joined() bool {
+	return r.LinesRead != r.Lines+r.OutsideWindow+r.FilteredOut
+}
+
+func writeJSONReport(w io.Writer, r report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// writeCSVReport prints one record per count. The record column says what a row counts:
+// a level, a level in one file, a level in one histogram bucket or a signature.
+func writeCSVReport(w io.Writer, r report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"record", "input", "level", "key", "count", "percentage", "first", "last"})
+
+	inputs := strings.Join(r.Inputs, ",")
+	cw.Write([]string{"lines", inputs, "", "", strconv.Itoa(r.Lines), "", "", ""})
+	cw.Write([]string{"lines_read", inputs, "", "", strconv.Itoa(r.LinesRead), "", "", ""})
+	cw.Write([]string{"outside_window", inputs, "", "", strconv.Itoa(r.OutsideWindow), "", "", ""})
+	cw.Write([]string{"filtered_out", inputs, "", "", strconv.Itoa(r.FilteredOut), "", "", ""})
+	for _, c := range r.Levels {
+		cw.Write([]string{"level", inputs, c.Level, "", strconv.Itoa(c.Count), formatPercentage(c.Percentage), "", ""})
+	}
+	for _, f := range r.Files {
+		cw.Write([]string{"file_lines", f.Path, "", f.Format, strconv.Itoa(f.Lines), "", "", ""})
+		for _, c := range f.Levels {
+			cw.Write([]string{"file_level", f.Path, c.Level, "", strconv.Itoa(c.Count), formatPercentage(c.Percentage), "", ""})
+		}
+	}
+	if r.Histogram != nil {
+		for _, b := range r.Histogram.Buckets {
+			start := b.Start.Format(time.RFC3339)
+			for _, name := range r.Histogram.Levels {
+				cw.Write([]string{"bucket", inputs, name, start, strconv.Itoa(b.Levels[name]), "", "", ""})
+			}
+		}
+	}
+	for _, sig := range r.Signatures {
+		cw.Write([]string{"signature", inputs, sig.Level, sig.Text, strconv.Itoa(sig.Count), "", sig.First.String(), sig.Last.String()})
+	}
+	if c := r.Comparison; c != nil {
+		// The percentage column holds the change from the baseline, empty when the baseline count is zero.
+		for _, d := range c.Levels {
+			cw.Write([]string{"level_delta", c.Baseline, d.Level, "", strconv.Itoa(d.Delta), changeCell(d), "", ""})
+		}
+		for _, d := range c.Signatures {
+			record := "signature_delta"
+			if d.New {
+				record = "new_signature"
+			}
+			cw.Write([]string{record, c.Baseline, d.Level, d.Text, strconv.Itoa(d.Delta), changeCell(d.countDelta), "", ""})
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func changeCell(d countDelta) string {
+	if d.Change == nil {
+		return ""
+	}
+	return formatPercentage(*d.Change)
+}
+
+func formatPercentage(p float64) string {
+	return strconv.FormatFloat(p, 'f', 2, 64)
+}
+
+// writeMarkdownReport prints the report as Markdown tables, for pasting into issues and job summaries.
+func writeMarkdownReport(out io.Writer, r report) error {
+	w := &errWriter{w: out}
+	fmt.Fprintf(w, "# Log analysis of %s\n\n", markdownCell(strings.Join(r.Inputs, ", ")))
+	fmt.Fprintf(w, "Format: %s. Total log lines: %d.", markdownCell(r.Format), r.Lines)
+	if r.joined() {
+		fmt.Fprintf(w, " Physical lines read: %d.", r.LinesRead)
+	}
+	if r.OutsideWindow > 0 {
+		fmt.Fprintf(w, " Lines outside the time window: %d.", r.OutsideWindow)
+	}
+	if r.FilteredOut > 0 {
+		fmt.Fprintf(w, " Lines filtered out: %d.", r.FilteredOut)
+	}
+	fmt.Fprintf(w, " Analyzed at %s.\n\n", r.AnalyzedAt.Format(time.DateTime))
+
+	fmt.Fprintln(w, "| Level | Entries | Percentage |")
+	fmt.Fprintln(w, "|---|---:|---:|")
+	for _, c := range r.Levels {
+		fmt.Fprintf(w, "| %s | %d | %s%% |\n", c.Level, c.Count, formatPercentage(c.Percentage))
+	}
+
+	if len(r.Files) > 0 {
+		fmt.Fprint(w, "\n## Per-file totals\n\n")
+		header, rule := "| File | Format | Lines |", "|---|---|---:|"
+		for _, c := range r.Levels {
+			header += " " + c.Level + " |"
+			rule += "---:|"
+		}
+		fmt.Fprintln(w, header)
+		fmt.Fprintln(w, rule)
+		for _, f := range r.Files {
+			row := fmt.Sprintf("| %s | %s | %d |", markdownCell(f.Path), markdownCell(f.Format), f.Lines)
+			for _, c := range f.Levels {
+				row += fmt.Sprintf(" %d |", c.Count)
+			}
+			fmt.Fprintln(w, row)
+		}
+	}
+
+	if h := r.Histogram; h != nil {
+		fmt.Fprintf(w, "\n## Lines per %s\n\n", h.Bucket)
+		switch {
+		case h.Error != "":
+			fmt.Fprintln(w, markdownCell(h.Error))
+		case len(h.Buckets) == 0:
+			fmt.Fprintln(w, "No timestamped lines to chart")
+		default:
+			header, rule := "| Start | Total |", "|---|---:|"
+			for _, name := range h.Levels {
+				header += " " + name + " |"
+				rule += "---:|"
+			}
+			fmt.Fprintln(w, header)
+			fmt.Fprintln(w, rule)
+			for _, b := range h.Buckets {
+				row := fmt.Sprintf("| %s | %d |", b.Start.Format(time.RFC3339), b.Total)
+				for _, name := range h.Levels {
+					row += fmt.Sprintf(" %d |", b.Levels[name])
+				}
+				fmt.Fprintln(w, row)
+			}
+		}
+	}
+
+	if len(r.Signatures) > 0 {
+		fmt.Fprint(w, "\n## Top signatures\n\n")
+		fmt.Fprintln(w, "| Level | Count | Signature | First line | Last line |")
+		fmt.Fprintln(w, "|---|---:|---|---|---|")
+		for _, sig := range r.Signatures {
+			fmt.Fprintf(w, "| %s | %d | %s | %s | %s |\n", sig.Level, sig.Count, markdownCell(sig.Text), markdownCell(sig.First.String()), markdownCell(sig.Last.String()))
+		}
+	}
+
+	if c := r.Comparison; c != nil {
+		fmt.Fprintf(w, "\n## Compared with %s\n\n", markdownCell(c.Baseline))
+		fmt.Fprintln(w, "| Level | Baseline | Current | Delta | Change |")
+		fmt.Fprintln(w, "|---|---:|---:|---:|---:|")
+		for _, d := range c.Levels {
+			fmt.Fprintf(w, "| %s | %d | %d | %+d | %s |\n", d.Level, d.Baseline, d.Current, d.Delta, signedChange(d))
+		}
+		if len(c.Signatures) > 0 {
+			fmt.Fprintln(w, "\n| Level | Signature | Baseline | Current | Delta | Change |")
+			fmt.Fprintln(w, "|---|---|---:|---:|---:|---:|")
+			for _, d := range c.Signatures {
+				text := markdownCell(d.Text)
+				if d.New {
+					text = "**NEW** " + text
+				}
+				fmt.Fprintf(w, "| %s | %s | %d | %d | %+d | %s |\n", d.Level, text, d.Baseline, d.Current, d.Delta, signedChange(d.countDelta))
+			}
+		}
+	}
+	return w.err
+}
+
+// markdownCell escapes text so it stays inside one table cell and masks such as <num> are not taken for HTML.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// writePrometheusReport prints the report in the Prometheus text exposition format,
+// for a node exporter textfile collector or a push gateway.
+func writePrometheusReport(out io.Writer, r report) error {
+	w := &errWriter{w: out}
+	metric := func(name, help, kind string) {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	}
+	sample := func(name string, value float64, labels ...string) {
+		var pairs []string
+		for i := 0; i+1 < len(labels); i += 2 {
+			pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], prometheusLabel.Replace(labels[i+1])))
+		}
+		if len(pairs) > 0 {
+			name += "{" + strings.Join(pairs, ",") + "}"
+		}
+		fmt.Fprintf(w, "%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+	}
+
+	metric("log_analysis_lines", "Entries counted, blank lines included; each line is an entry unless --multiline joins them.", "gauge")
+	sample("log_analysis_lines", float64(r.Lines))
+	metric("log_analysis_lines_read", "Physical lines read, counted or not.", "gauge")
+	sample("log_analysis_lines_read", float64(r.LinesRead))
+	metric("log_analysis_lines_outside_window", "Entries left out by --since and --until.", "gauge")
+	sample("log_analysis_lines_outside_window", float64(r.OutsideWindow))
+	metric("log_analysis_lines_filtered_out", "Entries left out by the filters.", "gauge")
+	sample("log_analysis_lines_filtered_out", float64(r.FilteredOut))
+
+	metric("log_analysis_level_lines", "Lines per canonical level.", "gauge")
+	for _, c := range r.Levels {
+		sample("log_analysis_level_lines", float64(c.Count), "level", c.Level)
+	}
+	metric("log_analysis_level_ratio", "Share of the lines per canonical level, from 0 to 1.", "gauge")
+	for _, c := range r.Levels {
+		sample("log_analysis_level_ratio", c.Percentage/100, "level", c.Level)
+	}
+
+	if len(r.Files) > 0 {
+		metric("log_analysis_file_level_lines", "Lines per canonical level in each input.", "gauge")
+		for _, f := range r.Files {
+			for _, c := range f.Levels {
+				sample("log_analysis_file_level_lines", float64(c.Count), "file", f.Path, "level", c.Level)
+			}
+		}
+	}
+
+	if r.Histogram != nil && len(r.Histogram.Buckets) > 0 {
+		metric("log_analysis_bucket_lines", "Lines per canonical level in each histogram bucket.", "gauge")
+		for _, b := range r.Histogram.Buckets {
+			for _, name := range r.Histogram.Levels {
+				sample("log_analysis_bucket_lines", float64(b.Levels[name]), "start", b.Start.Format(time.RFC3339), "level", name)
+			}
+		}
+	}
+
+	if len(r.Signatures) > 0 {
+		metric("log_analysis_signature_lines", "Lines of the most frequent message signatures per level.", "gauge")
+		for _, sig := range r.Signatures {
+			sample("log_analysis_signature_lines", float64(sig.Count), "level", sig.Level, "signature", sig.Text)
+		}
+	}
+
+	if c := r.Comparison; c != nil {
+		metric("log_analysis_level_delta", "Change in entries per canonical level since the baseline.", "gauge")
+		for _, d := range c.Levels {
+			sample("log_analysis_level_delta", float64(d.Delta), "level", d.Level)
+		}
+		if len(c.Signatures) > 0 {
+			metric("log_analysis_signature_delta", "Change in entries of error signatures since the baseline.", "gauge")
+			for _, d := range c.Signatures {
+				sample("log_analysis_signature_delta", float64(d.Delta), "level", d.Level, "signature", d.Text)
+			}
+		}
+	}
+
+	metric("log_analysis_timestamp_seconds", "When the analysis ran, in seconds since the epoch.", "gauge")
+	sample("log_analysis_timestamp_seconds", float64(r.AnalyzedAt.Unix()))
+	return w.err
+}
+
+// errWriter keeps the first error of the writes made through it and skips the writes after
+// it, so that a writer printing line by line can check for a full disk or a closed pipe once.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+// prometheusLabel escapes a label value the way the exposition format expects.
+var prometheusLabel = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// outputNames lists the --output values for messages.
+func outputNames() string {
+	var names []string
+	for name := range reportWriters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
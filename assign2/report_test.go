@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportOutputs(t *testing.T) {
+	levels := defaultLevelTable()
+	result, err := analyze(strings.NewReader("[INFO] up\n[ERROR] lost \"db|1\"\n[ERROR] lost \"db|2\"\n[WARN] slow\n"), config{Levels: levels})
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2024, 6, 12, 15, 4, 5, 0, time.UTC)
+	r := newReport([]string{"app.log"}, nil, result, levels, 3, at)
+
+	render := func(name string) string {
+		t.Helper()
+		var out strings.Builder
+		if err := reportWriters[name](&out, r); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		return out.String()
+	}
+
+	tests := []struct {
+		output string
+		want   []string
+	}{
+		{"text", []string{"ERROR: 2 entries\n", "ERROR percentage: 50.00%\n", "     2  lost <str>  (first line 2, last line 3)\n", "Analyzed at: 2024-06-12 15:04:05\n"}},
+		{"csv", []string{"record,input,level,key,count,percentage,first,last\n", "level,app.log,ERROR,,2,50.00,,\n", "signature,app.log,ERROR,lost <str>,2,,2,3\n"}},
+		{"markdown", []string{"| ERROR | 2 | 50.00% |\n", "| ERROR | 2 | lost &lt;str&gt; | 2 | 3 |\n"}},
+		{"prometheus", []string{"# TYPE log_analysis_level_lines gauge\n", "log_analysis_level_lines{level=\"ERROR\"} 2\n", "log_analysis_level_ratio{level=\"ERROR\"} 0.5\n", "log_analysis_timestamp_seconds 1.718204645e+09\n"}},
+	}
+	for _, tt := range tests {
+		got := render(tt.output)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: expected the report to contain %q, got:\n%s", tt.output, want, got)
+			}
+		}
+	}
+
+	var decoded report
+	if err := json.Unmarshal([]byte(render("json")), &decoded); err != nil {
+		t.Fatalf("json: %v", err)
+	}
+	if decoded.Lines != 4 || !decoded.AnalyzedAt.Equal(at) || len(decoded.Signatures) != 3 || decoded.Signatures[0].Level != "ERROR" {
+		t.Errorf("json: unexpected report %+v", decoded)
+	}
+
+	if got := prometheusLabel.Replace("a\"b\\c\nd"); got != `a\"b\\c\nd` {
+		t.Errorf("prometheusLabel = %s", got)
+	}
+}
+
+// shortWriter fails once it has taken limit bytes, like a full disk.
+type shortWriter struct{ limit int }
+
+func (s *shortWriter) Write(p []byte) (int, error) {
+	if len(p) > s.limit {
+		n := s.limit
+		s.limit = 0
+		return n, errors.New("no space left on device")
+	}
+	s.limit -= len(p)
+	return len(p), nil
+}
+
+func TestReportWriteErrors(t *testing.T) {
+	levels := defaultLevelTable()
+	result, err := analyze(strings.NewReader("[INFO] up\n[ERROR] lost\n"), config{Levels: levels})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newReport([]string{"app.log"}, nil, result, levels, 3, time.Now())
+
+	for name, write := range reportWriters {
+		if err := write(&shortWriter{limit: 40}, r); err == nil {
+			t.Errorf("%s: expected the write error to be returned", name)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ruleOps are the comparisons a rule can make, keyed by how they are written.
+var ruleOps = map[string]func(value, threshold float64) bool{
+	">":  func(v, t float64) bool { return v > t },
+	">=": func(v, t float64) bool { return v >= t },
+	"<":  func(v, t float64) bool { return v < t },
+	"<=": func(v, t float64) bool { return v <= t },
+	"==": func(v, t float64) bool { return v == t },
+	"!=": func(v, t float64) bool { return v != t },
+}
+
+// errNoTimestamps is returned for a rule with a window when no line had a timestamp to place it in one.
+var errNoTimestamps = errors.New("no timestamped lines to place in a window")
+
+// rule is one alert condition, such as `ERROR percentage > 5`, `signature "Timeout" count > 10 in 5m`
+// or `no INFO in 10m`. The rule is violated while its condition holds.
+type rule struct {
+	Name string `yaml:"name"`
+	When string `yaml:"when"`
+
+	level     string // canonical level counted, empty for a signature rule
+	signature string // normalized text the signatures counted contain
+	metric    string // count or percentage
+	op        string
+	threshold float64
+	window    time.Duration // zero to count the whole input
+}
+
+// ruleSet is a rules file:
+//
+//	webhook: https://hooks.example.com/alerts
+//	rules:
+//	  - name: error rate
+//	    when: ERROR percentage > 5
+//	  - when: signature "Timeout" count > 10 in 5m
+//	  - when: no INFO in 10m
+//
+// JSON with the same keys works too.
+type ruleSet struct {
+	Webhook string `yaml:"webhook"` // where --follow posts alerts, optional
+	Rules   []rule `yaml:"rules"`
+}
+
+// loadRules reads a rules file and checks every condition against the level table.
+func loadRules(path string, levels *levelTable) (*ruleSet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules: %w", err)
+	}
+
+	var rs ruleSet
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rs); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("reading rules %s: %w", path, err)
+	}
+	if len(rs.Rules) == 0 {
+		return nil, fmt.Errorf("rules file %s has no rules", path)
+	}
+	for i := range rs.Rules {
+		r := &rs.Rules[i]
+		if err := r.parse(levels); err != nil {
+			return nil, fmt.Errorf("rule %d of %s: %w", i+1, path, err)
+		}
+		if r.Name == "" {
+			r.Name = r.When
+		}
+	}
+	return &rs, nil
+}
+
+// parse reads the condition in r.When.
+func (r *rule) parse(levels *levelTable) error {
+	tokens, err := splitRule(r.When)
+	if err != nil {
+		return err
+	}
+	next := func() string {
+		if len(tokens) == 0 {
+			return ""
+		}
+		t := tokens[0]
+		tokens = tokens[1:]
+		return t
+	}
+
+	negated := len(tokens) > 0 && strings.EqualFold(tokens[0], "no")
+	if negated {
+		next()
+	}
+
+	switch subject := next(); {
+	case subject == "":
+		return fmt.Errorf("%q names no level or signature", r.When)
+	case strings.EqualFold(subject, "signature"):
+		quoted := next()
+		text, err := strconv.Unquote(quoted)
+		if err != nil || !strings.HasPrefix(quoted, `"`) {
+			return fmt.Errorf("%q: signature takes a quoted text", r.When)
+		}
+		if r.signature = normalizeMessage(text); r.signature == "" {
+			return fmt.Errorf("%q: signature text is empty", r.When)
+		}
+	default:
+		if r.level = levels.classify(subject); r.level == unclassified && !strings.EqualFold(subject, unclassified) {
+			return fmt.Errorf("%q: unknown level %s", r.When, subject)
+		}
+	}
+
+	if negated {
+		r.metric, r.op, r.threshold = "count", "==", 0
+	} else {
+		if r.metric = strings.ToLower(next()); r.metric != "count" && r.metric != "percentage" {
+			return fmt.Errorf("%q: expected count or percentage", r.When)
+		}
+		if r.op = next(); ruleOps[r.op] == nil {
+			return fmt.Errorf("%q: expected a comparison such as > or <=", r.When)
+		}
+		if r.threshold, err = strconv.ParseFloat(next(), 64); err != nil {
+			return fmt.Errorf("%q: expected a number after %s", r.When, r.op)
+		}
+	}
+
+	if len(tokens) > 0 {
+		if !strings.EqualFold(next(), "in") {
+			return fmt.Errorf("%q: expected in and a window after the condition", r.When)
+		}
+		if r.window, err = time.ParseDuration(next()); err != nil || r.window < time.Second {
+			return fmt.Errorf("%q: the window must be a duration of a second or more, such as 5m", r.When)
+		}
+	}
+	if len(tokens) > 0 {
+		return fmt.Errorf("%q: unexpected %q", r.When, tokens[0])
+	}
+	return nil
+}
+
+// splitRule splits a condition into words, keeping double-quoted text together with its quotes.
+func splitRule(s string) ([]string, error) {
+	var tokens []string
+	for s = strings.TrimSpace(s); s != ""; s = strings.TrimSpace(s) {
+		if s[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("unterminated quote in %q", s)
+			}
+			tokens = append(tokens, quoted)
+			s = s[len(quoted):]
+			continue
+		}
+		end := strings.IndexAny(s, " \t")
+		if end < 0 {
+			end = len(s)
+		}
+		tokens = append(tokens, s[:end])
+		s = s[end:]
+	}
+	return tokens, nil
+}
+
+// evaluate returns the value the rule compares and whether it is violated. Windows end at now,
+// or at the newest timestamp in the input when now is zero.
+func (r rule) evaluate(result analysis, now time.Time) (float64, bool, error) {
+	var count, lines int
+	if r.window == 0 {
+		lines = result.Lines
+		if r.level != "" {
+			count = result.Levels[r.level]
+		} else {
+			for _, sigs := range result.Signatures {
+				for text, sig := range sigs {
+					if strings.Contains(text, r.signature) {
+						count += sig.Count
+					}
+				}
+			}
+		}
+	} else {
+		if result.Recent == nil || result.Recent.latest.IsZero() {
+			return 0, false, errNoTimestamps
+		}
+		if now.IsZero() {
+			now = result.Recent.latest
+		}
+		key := r.level
+		if key == "" {
+			key = signatureKey(r.signature)
+		}
+		count, lines = result.Recent.count(key, r.window, now)
+	}
+
+	value := float64(count)
+	if r.metric == "percentage" {
+		value = 0
+		if lines > 0 {
+			value = float64(count) / float64(lines) * 100
+		}
+	}
+	return value, ruleOps[r.op](value, r.threshold), nil
+}
+
+// ruleResult is the outcome of one rule.
+type ruleResult struct {
+	Rule     rule
+	Value    float64
+	Violated bool
+	Err      error // set when the rule could not be evaluated
+}
+
+// evaluate checks every rule, windows ending at now as for rule.evaluate.
+func (rs *ruleSet) evaluate(result analysis, now time.Time) []ruleResult {
+	var results []ruleResult
+	for _, r := range rs.Rules {
+		value, violated, err := r.evaluate(result, now)
+		results = append(results, ruleResult{Rule: r, Value: value, Violated: violated, Err: err})
+	}
+	return results
+}
+
+// writeViolations lists the violated rules and the rules that could not be evaluated,
+// and reports whether any rule was violated.
+func writeViolations(w io.Writer, results []ruleResult) bool {
+	var violated []string
+	for _, res := range results {
+		switch {
+		case res.Err != nil:
+			fmt.Fprintf(w, "Rule %q not evaluated: %v\n", res.Rule.Name, res.Err)
+		case res.Violated:
+			name := res.Rule.When
+			if res.Rule.Name != res.Rule.When {
+				name = res.Rule.Name + ": " + res.Rule.When
+			}
+			violated = append(violated, fmt.Sprintf("  %s (value %s)", name, strconv.FormatFloat(res.Value, 'f', -1, 64)))
+		}
+	}
+
+	if len(violated) > 0 {
+		fmt.Fprintln(w, "Violated rules:")
+		fmt.Fprintln(w, strings.Join(violated, "\n"))
+	}
+	return len(violated) > 0
+}
+
+// timeline keeps per-second counts of recent lines for rules with a window: lines, lines per level
+// and lines whose signature contains the text of a signature rule. Seconds that fall out of
+// the longest window before the newest timestamp are dropped.
+type timeline struct {
+	span       time.Duration
+	signatures []string
+	latest     time.Time
+	seconds    map[int64]map[string]int // unix second to counts by level or signatureKey, "" for all lines
+}
+
+// signatureKey keeps the counts of a signature text apart from those of a level with the same name.
+func signatureKey(text string) string {
+	return "signature:" + text
+}
+
+// newTimeline returns the timeline the rules need, or nil when none has a window.
+func (rs *ruleSet) newTimeline() *timeline {
+	if rs == nil {
+		return nil
+	}
+	t := &timeline{seconds: map[int64]map[string]int{}}
+	for _, r := range rs.Rules {
+		if r.window == 0 {
+			continue
+		}
+		t.span = max(t.span, r.window)
+		if r.signature != "" {
+			t.signatures = append(t.signatures, r.signature)
+		}
+	}
+	if t.span == 0 {
+		return nil
+	}
+	return t
+}
+
+// add counts a line of the given level and signature at at.
+func (t *timeline) add(at time.Time, level, signature string) {
+	second := at.Unix()
+	if t.seconds[second] == nil {
+		t.seconds[second] = map[string]int{}
+	}
+	counts := t.seconds[second]
+	counts[""]++
+	counts[level]++
+	for _, text := range t.signatures {
+		if strings.Contains(signature, text) {
+			counts[signatureKey(text)]++
+		}
+	}
+	if at.After(t.latest) {
+		t.latest = at
+		t.prune()
+	}
+}
+
+// merge adds the counts of o, which must be for the same rules.
+func (t *timeline) merge(o *timeline) {
+	for second, counts := range o.seconds {
+		if t.seconds[second] == nil {
+			t.seconds[second] = map[string]int{}
+		}
+		for key, n := range counts {
+			t.seconds[second][key] += n
+		}
+	}
+	if o.latest.After(t.latest) {
+		t.latest = o.latest
+	}
+	t.prune()
+}
+
+func (t *timeline) prune() {
+	oldest := t.latest.Add(-t.span).Unix()
+	for second := range t.seconds {
+		if second <= oldest {
+			delete(t.seconds, second)
+		}
+	}
+}
+
+// count returns the lines counted under key, and all lines, in the window of the given width ending at now.
+func (t *timeline) count(key string, window time.Duration, now time.Time) (n, lines int) {
+	from, to := now.Add(-window).Unix(), now.Unix()
+	for second, counts := range t.seconds {
+		if second > from && second <= to {
+			n += counts[key]
+			lines += counts[""]
+		}
+	}
+	return n, lines
+}
+
+// alert is the body posted to the webhook when a rule starts or stops being violated.
+type alert struct {
+	Rule      string    `json:"rule"`
+	Condition string    `json:"condition"`
+	Status    string    `json:"status"` // firing or resolved
+	Value     float64   `json:"value"`
+	File      string    `json:"file"`
+	At        time.Time `json:"at"`
+}
+
+// notifier posts an alert to a webhook each time a rule starts or stops being violated.
+type notifier struct {
+	url    string
+	file   string
+	client *http.Client
+	firing map[string]bool
+}
+
+func newNotifier(url, file string) *notifier {
+	return &notifier{url: url, file: file, client: &http.Client{Timeout: 10 * time.Second}, firing: map[string]bool{}}
+}
+
+// notify posts the rules whose state changed since the last call. Rules that could not be
+// evaluated keep their state.
+func (n *notifier) notify(results []ruleResult, now time.Time) error {
+	var errs []error
+	for _, res := range results {
+		if res.Err != nil || res.Violated == n.firing[res.Rule.Name] {
+			continue
+		}
+
+		a := alert{Rule: res.Rule.Name, Condition: res.Rule.When, Status: "resolved", Value: res.Value, File: n.file, At: now}
+		if res.Violated {
+			a.Status = "firing"
+		}
+		if err := n.post(a); err != nil {
+			errs = append(errs, fmt.Errorf("posting alert for rule %q: %w", res.Rule.Name, err))
+			continue
+		}
+		n.firing[res.Rule.Name] = res.Violated
+	}
+	return errors.Join(errs...)
+}
+
+func (n *notifier) post(a alert) error {
+	body, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	resp, err := n.client.Post(n.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		when    string
+		want    rule
+		wantErr bool
+	}{
+		{when: "ERROR percentage > 5", want: rule{level: "ERROR", metric: "percentage", op: ">", threshold: 5}},
+		{when: `signature "Timeout after 30s" count >= 10 in 5m`, want: rule{signature: "Timeout after <num>s", metric: "count", op: ">=", threshold: 10, window: 5 * time.Minute}},
+		{when: "no warn in 10m", want: rule{level: "WARNING", metric: "count", op: "==", threshold: 0, window: 10 * time.Minute}},
+		{when: "no UNCLASSIFIED", want: rule{level: unclassified, metric: "count", op: "==", threshold: 0}},
+		{when: "BOGUS count > 1", wantErr: true},
+		{when: "ERROR ratio > 1", wantErr: true},
+		{when: "ERROR count => 1", wantErr: true},
+		{when: "ERROR count > many", wantErr: true},
+		{when: "ERROR count > 1 in 500ms", wantErr: true},
+		{when: "ERROR count > 1 during 5m", wantErr: true},
+		{when: `signature Timeout count > 1`, wantErr: true},
+		{when: `signature "Timeout count > 1`, wantErr: true},
+		{when: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		r := rule{When: tt.when}
+		err := r.parse(defaultLevelTable())
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parse(%q): expected an error, got %+v", tt.when, r)
+			}
+			continue
+		}
+		tt.want.When = tt.when
+		if err != nil || r != tt.want {
+			t.Errorf("parse(%q) = %+v, %v; expected %+v", tt.when, r, err, tt.want)
+		}
+	}
+}
+
+func TestEvaluateRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	rulesJSON := `{"rules": [
+		{"name": "error rate", "when": "ERROR percentage > 25"},
+		{"when": "signature \"Timeout\" count > 1 in 5m"},
+		{"when": "signature \"Timeout\" count > 2"},
+		{"when": "no INFO in 2m"},
+		{"when": "no DEBUG"}
+	]}`
+	if err := os.WriteFile(path, []byte(rulesJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rs, err := loadRules(path, defaultLevelTable())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	log := strings.Join([]string{
+		"2024-06-12T10:00:00Z [INFO] start",
+		"2024-06-12T10:00:30Z [ERROR] Timeout after 30s",
+		"2024-06-12T10:06:00Z [ERROR] Timeout after 10s",
+		"2024-06-12T10:07:00Z [ERROR] Timeout after 12s",
+		"2024-06-12T10:08:00Z [WARN] slow",
+	}, "\n")
+	cfg := config{Parser: bracketParser{}, Levels: defaultLevelTable(), Rules: rs}
+	result, err := analyze(strings.NewReader(log), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		value    float64
+		violated bool
+	}{{60, true}, {2, true}, {3, true}, {0, true}, {0, true}}
+	results := rs.evaluate(result, time.Time{})
+	for i, res := range results {
+		if res.Err != nil || res.Value != want[i].value || res.Violated != want[i].violated {
+			t.Errorf("rule %q = %v, %v, %v; expected %v, %v", res.Rule.Name, res.Value, res.Violated, res.Err, want[i].value, want[i].violated)
+		}
+	}
+
+	// Windows end at the time given, in follow mode the current time.
+	late := rs.evaluate(result, time.Date(2024, 6, 12, 10, 12, 30, 0, time.UTC))
+	if late[1].Value != 0 || late[1].Violated || !late[3].Violated {
+		t.Errorf("Expected only the INFO window to be violated at 10:12:30, got %+v and %+v", late[1], late[3])
+	}
+
+	var out strings.Builder
+	if !writeViolations(&out, results) || !strings.Contains(out.String(), "  error rate: ERROR percentage > 25 (value 60)\n") {
+		t.Errorf("Unexpected violations:\n%s", out.String())
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	var alerts []alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			t.Errorf("bad alert body: %v", err)
+		}
+		alerts = append(alerts, a)
+	}))
+	defer server.Close()
+
+	n := newNotifier(server.URL, "app.log")
+	r := rule{Name: "errors", When: "ERROR count > 0"}
+	now := time.Now()
+	for _, violated := range []bool{false, true, true, false} {
+		if err := n.notify([]ruleResult{{Rule: r, Violated: violated}}, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if len(alerts) != 2 || alerts[0].Status != "firing" || alerts[1].Status != "resolved" || alerts[0].File != "app.log" {
+		t.Errorf("Expected one firing and one resolved alert, got %+v", alerts)
+	}
+}
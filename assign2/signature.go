@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Masks applied by normalizeMessage, in order: quoted strings first so their contents are
+// not masked piecemeal, and numbers last so they do not break up UUIDs and addresses.
+var messageMasks = []struct {
+	pattern *regexp.Regexp
+	mask    string
+}{
+	{regexp.MustCompile(`"(?:[^"\\]|\\.)*"|'(?:[^'\\]|\\.)*'`), "<str>"},
+	{regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`), "<uuid>"},
+	{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`(?i)\b(?:[0-9a-f]{1,4}:){7}[0-9a-f]{1,4}\b` +
+		`|\b[0-9a-f]{1,4}(?::[0-9a-f]{1,4})*::(?:[0-9a-f]{1,4}(?::[0-9a-f]{1,4})*\b)?` +
+		`|::[0-9a-f]{1,4}(?::[0-9a-f]{1,4})*\b`), "<ip>"},
+	{regexp.MustCompile(`(?i)\b0x[0-9a-f]+\b|[-+]?\b\d+(?:\.\d+)?`), "<num>"},
+}
+
+// normalizeMessage turns a message into its signature by masking the parts that vary
+// between occurrences of the same event, such as "Timeout after 30s" and "Timeout after 45s".
+func normalizeMessage(msg string) string {
+	for _, m := range messageMasks {
+		msg = m.pattern.ReplaceAllString(msg, m.mask)
+	}
+	return strings.Join(strings.Fields(msg), " ")
+}
+
+// position is a line in one of the inputs; File is empty while a single log is being read.
+type position struct {
+	File string `json:"file,omitempty"`
+	Line int    `json:"line"`
+}
+
+func (p position) String() string {
+	if p.File == "" {
+		return fmt.Sprint(p.Line)
+	}
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+// signature counts the lines of one level that share a normalized message.
+type signature struct {
+	Text  string   `json:"signature"`
+	Count int      `json:"count"`
+	First position `json:"first"`
+	Last  position `json:"last"`
+}
+
+// signatureSet holds the signatures of every level, keyed by level and then by signature text.
+type signatureSet map[string]map[string]*signature
+
+// add records a line of the given level and returns its signature, which is empty for a blank message.
+func (s signatureSet) add(level, message string, at position) string {
+	text := normalizeMessage(message)
+	if text == "" {
+		return ""
+	}
+
+	if s[level] == nil {
+		s[level] = map[string]*signature{}
+	}
+	sig, ok := s[level][text]
+	if !ok {
+		sig = &signature{Text: text, First: at}
+		s[level][text] = sig
+	}
+	sig.Count++
+	sig.Last = at
+	return text
+}
+
+// merge adds the signatures of o, which must come from later in the input than those of s.
+func (s signatureSet) merge(o signatureSet) {
+	for level, sigs := range o {
+		if s[level] == nil {
+			s[level] = map[string]*signature{}
+		}
+		for text, sig := range sigs {
+			mine, ok := s[level][text]
+			if !ok {
+				copied := *sig
+				s[level][text] = &copied
+				continue
+			}
+			mine.Count += sig.Count
+			mine.Last = sig.Last
+		}
+	}
+}
+
+// shift moves every line number down by offset and tags the positions with file.
+func (s signatureSet) shift(offset int, file string) {
+	for _, sigs := range s {
+		for _, sig := range sigs {
+			sig.First = position{File: file, Line: sig.First.Line + offset}
+			sig.Last = position{File: file, Line: sig.Last.Line + offset}
+		}
+	}
+}
+
+// top returns the n most frequent signatures of a level, ties going to the one seen first.
+func (s signatureSet) top(level string, n int) []signature {
+	var sigs []signature
+	for _, sig := range s[level] {
+		sigs = append(sigs, *sig)
+	}
+	sort.Slice(sigs, func(i, j int) bool {
+		if sigs[i].Count != sigs[j].Count {
+			return sigs[i].Count > sigs[j].Count
+		}
+		if sigs[i].First.File != sigs[j].First.File {
+			return sigs[i].First.File < sigs[j].First.File
+		}
+		return sigs[i].First.Line < sigs[j].First.Line
+	})
+	if len(sigs) > n {
+		sigs = sigs[:n]
+	}
+	return sigs
+}
+
+// signatureReport is a signature with the level it was counted under.
+type signatureReport struct {
+	Level string `json:"level"`
+	signature
+}
+
+// report returns the top n signatures of every level that has any, most severe level first.
+func (s signatureSet) report(levels *levelTable, n int) []signatureReport {
+	names := append(append([]string{}, levels.names...), unclassified)
+	sort.SliceStable(names, func(i, j int) bool { return levels.rank(names[i]) > levels.rank(names[j]) })
+
+	var out []signatureReport
+	for _, name := range names {
+		for _, sig := range s.top(name, n) {
+			out = append(out, signatureReport{Level: name, signature: sig})
+		}
+	}
+	return out
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestNormalizeMessage(t *testing.T) {
+	tests := []struct {
+		msg  string
+		want string
+	}{
+		{"Timeout after 30s", "Timeout after <num>s"},
+		{"Timeout after 45.5s", "Timeout after <num>s"},
+		{"user 42 logged in from 10.0.0.7:5432", "user <num> logged in from <ip>"},
+		{"request 3f2b6a1c-9d4e-4c1a-8b7f-0a1b2c3d4e5f failed", "request <uuid> failed"},
+		{`open "/var/log/app 2.log": no such file`, "open <str>: no such file"},
+		{"peer fe80::1ff:fe23:4567:890a unreachable", "peer <ip> unreachable"},
+		{"bound to ::1 and 2001:db8:0:0:0:0:2:1", "bound to <ip> and <ip>"},
+		{"std::vector at 12:30:45", "std::vector at <num>:<num>:<num>"},
+		{"pointer 0xdeadBEEF freed", "pointer <num> freed"},
+		{"  spaced   out  ", "spaced out"},
+		{"Server started", "Server started"},
+	}
+	for _, test := range tests {
+		if got := normalizeMessage(test.msg); got != test.want {
+			t.Errorf("normalizeMessage(%q) = %q, expected %q", test.msg, got, test.want)
+		}
+	}
+}
+
+func TestSignaturesOfFixture(t *testing.T) {
+	f, err := os.Open("testdata/log.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	result, err := analyze(f, config{Levels: defaultLevelTable()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []signature{
+		{Text: "Failed to connect to DB", Count: 2, First: position{Line: 2}, Last: position{Line: 7}},
+		{Text: "Timeout occurred", Count: 2, First: position{Line: 4}, Last: position{Line: 9}},
+	}
+	got := result.Signatures.top("ERROR", 5)
+	if len(got) != len(want) {
+		t.Fatalf("Expected %d ERROR signatures, got %+v", len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("ERROR signature %d = %+v, expected %+v", i, got[i], want[i])
+		}
+	}
+
+	if got := result.Signatures.top("INFO", 1); len(got) != 1 || got[0].Text != "server ended" {
+		t.Errorf("Expected the top INFO signature to be the repeated shutdown, got %+v", got)
+	}
+}
@@ -2,16 +2,28 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
 	"testing"
+
+	"assignment/tasks"
 )
 
+func newTestTracker(t *testing.T) *TaskTracker {
+	t.Helper()
+	tracker, err := NewTaskTracker(tasks.NewMemoryStore())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tracker
+}
+
 func TestCompleteTask(t *testing.T) {
-	tracker := NewTaskTracker()
+	tracker := newTestTracker(t)
 	tracker.AddTask("Read a book")
 	tracker.AddTask("Play")
 	tracker.AddTask("Clean")
 
-	success, msg := tracker.CompleteTask(2)
+	success, msg, _ := tracker.CompleteTask(2)
 	if !success {
 		t.Errorf("Expected task 2 to be completed successfully, but it failed: %s", msg)
 	}
@@ -23,7 +35,7 @@ func TestCompleteTask(t *testing.T) {
 		t.Errorf("Task 2 should be marked as completed")
 	}
 
-	success, msg = tracker.CompleteTask(2)
+	success, msg, _ = tracker.CompleteTask(2)
 	if success {
 		t.Errorf("Expected task 2 to not be completed, but it succeeded.")
 	}
@@ -31,7 +43,7 @@ func TestCompleteTask(t *testing.T) {
 		t.Errorf("Incorrect already completed message '%s'", msg)
 	}
 
-	success, msg = tracker.CompleteTask(99)
+	success, msg, _ = tracker.CompleteTask(99)
 	if success {
 		t.Errorf("Expected task 99 to not be completed, but it succeeded.")
 	}
@@ -48,7 +60,7 @@ func TestCompleteTask(t *testing.T) {
 }
 
 func TestListTasks(t *testing.T) {
-	tracker := NewTaskTracker()
+	tracker := newTestTracker(t)
 
 	expected := "Pending Tasks:\nNo pending tasks."
 	if tracker.ListTasks() != expected {
@@ -79,3 +91,39 @@ func TestListTasks(t *testing.T) {
 		t.Errorf("Expected '%s', got '%s'", expected, tracker.ListTasks())
 	}
 }
+
+func TestTrackerRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	open := func() *TaskTracker {
+		t.Helper()
+		store, err := tasks.OpenJSONStore(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tracker, err := NewTaskTracker(store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tracker
+	}
+
+	tracker := open()
+	tracker.AddTask("Task A")
+	tracker.AddTask("Task B")
+	tracker.AddTask("Task C")
+	tracker.CompleteTask(2)
+	tracker.store.Delete(3)
+
+	tracker = open()
+	expected := "Pending Tasks:\n1: Task A\n"
+	if tracker.ListTasks() != expected {
+		t.Errorf("Expected '%s' after a restart, got '%s'", expected, tracker.ListTasks())
+	}
+	added, err := tracker.AddTask("Task D")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if added.ID != 4 {
+		t.Errorf("Expected the next ID to follow the deleted task 3, got %d", added.ID)
+	}
+}
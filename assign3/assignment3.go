@@ -2,55 +2,69 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
 	"os"
 	"strconv"
 	"strings"
+
+	"assignment/tasks"
 )
 
 // Task represents a single task in our tracker.
-type Task struct {
-	ID          int
-	Description string
-	Completed   bool
-}
+type Task = tasks.Task
 
 // TaskTracker manages the collection of tasks and generates unique IDs.
+// Every change is written to its store, so the tasks survive a restart.
 type TaskTracker struct {
+	store     tasks.TaskStore
 	tasks     []Task
 	nextIDGen func() int
 }
 
-// idGenerator is a closure that generates unique sequential integer IDs.
+// idGenerator is a closure that generates unique sequential integer IDs, starting after last.
 // It encapsulates the 'id' counter, so it's not a global variable.
-func idGenerator() func() int {
-	id := 0
+func idGenerator(last int) func() int {
+	id := last
 	return func() int {
 		id++
 		return id
 	}
 }
 
-// NewTaskTracker creates and initializes a new TaskTracker instance.
-// It also sets up the unique ID generator.
-func NewTaskTracker() *TaskTracker {
-	return &TaskTracker{
-		tasks:     []Task{},
-		nextIDGen: idGenerator(),
+// NewTaskTracker creates a TaskTracker holding the tasks already in the store.
+// The ID generator continues after the highest ID the store ever saved, deleted tasks
+// included, so IDs are not reused after a restart.
+func NewTaskTracker(store tasks.TaskStore) (*TaskTracker, error) {
+	stored, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+	lastID, err := store.LastID()
+	if err != nil {
+		return nil, err
 	}
+	return &TaskTracker{
+		store:     store,
+		tasks:     append([]Task{}, stored...),
+		nextIDGen: idGenerator(lastID),
+	}, nil
 }
 
 // AddTask adds a new task to the tracker and returns the added Task.
 // It uses a pointer receiver (*TaskTracker) because it modifies the TaskTracker's state (its 'tasks' slice).
-func (tt *TaskTracker) AddTask(description string) Task {
+func (tt *TaskTracker) AddTask(description string) (Task, error) {
 	newID := tt.nextIDGen()
 	newTask := Task{
 		ID:          newID,
 		Description: description,
 		Completed:   false,
 	}
+	if err := tt.store.Save(newTask); err != nil {
+		return Task{}, err
+	}
 	tt.tasks = append(tt.tasks, newTask)
-	return newTask
+	return newTask, nil
 }
 
 // ListTasks displays all pending tasks.
@@ -73,19 +87,24 @@ func (tt *TaskTracker) ListTasks() string {
 
 // CompleteTask marks a task as completed given its ID.
 // It returns a boolean indicating if the task was found and its completion status was changed,
-// and a string message describing the outcome.
+// and a string message describing the outcome. The error is set when the store could not be updated.
 // It uses a pointer receiver (*TaskTracker) because it modifies the state of a Task within the tracker's slice.
-func (tt *TaskTracker) CompleteTask(id int) (bool, string) {
+func (tt *TaskTracker) CompleteTask(id int) (bool, string, error) {
 	for i := range tt.tasks {
 		if tt.tasks[i].ID == id {
 			if tt.tasks[i].Completed {
-				return false, fmt.Sprintf("Task %d is already completed.", id)
+				return false, fmt.Sprintf("Task %d is already completed.", id), nil
 			}
-			tt.tasks[i].Completed = true
-			return true, fmt.Sprintf("Marking task %d as completed: %s", id, tt.tasks[i].Description)
+			completed := tt.tasks[i]
+			completed.Completed = true
+			if err := tt.store.Save(completed); err != nil {
+				return false, fmt.Sprintf("Task %d could not be saved.", id), err
+			}
+			tt.tasks[i] = completed
+			return true, fmt.Sprintf("Marking task %d as completed: %s", id, completed.Description), nil
 		}
 	}
-	return false, fmt.Sprintf("Task with ID %d not found.", id)
+	return false, fmt.Sprintf("Task with ID %d not found.", id), nil
 }
 
 // displayMenu prints the interactive menu options to the console.
@@ -107,7 +126,21 @@ func getUserInput() string {
 
 // main function orchestrates the CLI interaction.
 func main() {
-	tracker := NewTaskTracker()
+	storeSpec := flag.String("store", "json:tasks.json", "where tasks are kept: memory, json:PATH or sqlite:PATH")
+	flag.Parse()
+
+	store, err := tasks.Open(*storeSpec)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer store.Close()
+
+	tracker, err := NewTaskTracker(store)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
 	for {
 		displayMenu()
@@ -126,7 +159,11 @@ func main() {
 				fmt.Println("Task description cannot be empty.")
 				continue
 			}
-			addedTask := tracker.AddTask(description)
+			addedTask, err := tracker.AddTask(description)
+			if err != nil {
+				fmt.Println("Task could not be saved:", err)
+				continue
+			}
 			fmt.Printf("Task Added: %d - %s\n", addedTask.ID, addedTask.Description)
 		case 2:
 			fmt.Println(tracker.ListTasks())
@@ -138,8 +175,11 @@ func main() {
 				fmt.Println("Invalid ID. Please enter a valid number.")
 				continue
 			}
-			_, msg := tracker.CompleteTask(id)
+			_, msg, err := tracker.CompleteTask(id)
 			fmt.Println(msg)
+			if err != nil {
+				fmt.Println(err)
+			}
 		case 4:
 			fmt.Println("Exiting Task Tracker. Goodbye!")
 			return
@@ -2,11 +2,15 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
 	"strconv"
+	"sync"
 	"time"
+
+	"assignment/tasks"
 )
 
 var (
@@ -38,7 +42,7 @@ func httpmark(w http.ResponseWriter, r *http.Request, tracker *TaskTracker) {
 		return
 	}
 
-	if id > len(tracker.tasks) || id <= 0 {
+	if id > tracker.maxID() || id <= 0 {
 		w.WriteHeader(http.StatusBadRequest)
 
 		_, err = w.Write([]byte("Please Enter the valid ID"))
@@ -48,7 +52,11 @@ func httpmark(w http.ResponseWriter, r *http.Request, tracker *TaskTracker) {
 		}
 	}
 
-	_, message := tracker.CompleteTask(id)
+	_, message, err := tracker.CompleteTask(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
 	w.WriteHeader(http.StatusOK)
 	_, err = w.Write([]byte(message))
@@ -76,15 +84,20 @@ func httppostTask(w http.ResponseWriter, r *http.Request, tracker *TaskTracker)
 		return
 	}
 
-	tracker.AddTask(task)
+	_, err := tracker.AddTask(task)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
 	w.WriteHeader(http.StatusCreated)
 }
 
 func httpListtask(w http.ResponseWriter, _ *http.Request, tracker *TaskTracker) {
-	tasks := tracker.ListTasks()
+	list := tracker.ListTasks()
 
 	w.WriteHeader(http.StatusOK)
-	_, err := w.Write([]byte(tasks))
+	_, err := w.Write([]byte(list))
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -113,14 +126,14 @@ func parseAndValidateID(r *http.Request, maxID int) (int, error) {
 }
 
 func httpDelete(w http.ResponseWriter, r *http.Request, tracker *TaskTracker) {
-	maxID := len(tracker.tasks)
+	maxID := tracker.maxID()
 
 	id, err := parseAndValidateID(r, maxID)
 	if err != nil {
 		return
 	}
 
-	if id > len(tracker.tasks) || id <= 0 {
+	if id > maxID || id <= 0 {
 		w.WriteHeader(http.StatusBadRequest)
 		_, err = w.Write([]byte("Please Enter a valid ID within range"))
 
@@ -132,22 +145,21 @@ func httpDelete(w http.ResponseWriter, r *http.Request, tracker *TaskTracker) {
 		return
 	}
 
-	indexToDelete := id - 1
-	if indexToDelete <= 0 && indexToDelete > len(tracker.tasks) {
-		http.Error(w, "Task not found for deletion", http.StatusNotFound) // Use 404 for not found
+	found, err := tracker.DeleteTask(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if len(tracker.tasks) == 1 {
-		tracker.tasks = []Task{}
-	} else {
-		tracker.tasks = append(tracker.tasks[:indexToDelete], tracker.tasks[indexToDelete+1:]...)
+	if !found {
+		http.Error(w, "Task not found for deletion", http.StatusNotFound) // Use 404 for not found
+		return
 	}
 
-	tasks := tracker.ListTasks()
+	list := tracker.ListTasks()
 
 	w.WriteHeader(http.StatusOK)
-	_, err = w.Write([]byte("Task deleted successfully. Updated list:\n" + tasks))
+	_, err = w.Write([]byte("Task deleted successfully. Updated list:\n" + list))
 
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -164,7 +176,7 @@ func httpListbyID(w http.ResponseWriter, r *http.Request, tracker *TaskTracker)
 		return
 	}
 
-	if id > len(tracker.tasks) || id < 0 {
+	if id > tracker.maxID() || id < 0 {
 		_, err = w.Write([]byte("Please Enter the valid ID"))
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -174,18 +186,17 @@ func httpListbyID(w http.ResponseWriter, r *http.Request, tracker *TaskTracker)
 		return
 	}
 
-	for _, task := range tracker.tasks {
-		if id == task.ID {
-			w.WriteHeader(http.StatusOK)
-			_, err = w.Write([]byte(task.Description))
-
-			if err != nil {
-				http.Error(w, err.Error(), http.StatusInternalServerError)
-				return
-			}
+	task, found := tracker.taskByID(id)
+	if found {
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write([]byte(task.Description))
 
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return
 		}
+
+		return
 	}
 
 	w.WriteHeader(http.StatusBadRequest)
@@ -198,22 +209,23 @@ func httpListbyID(w http.ResponseWriter, r *http.Request, tracker *TaskTracker)
 }
 
 // Task represents a single task in our tracker.
-type Task struct {
-	ID          int
-	Description string
-	Completed   bool
-}
+type Task = tasks.Task
 
 // TaskTracker manages the collection of tasks and generates unique IDs.
+// Every change is written to its store, so the tasks survive a restart.
+// The HTTP handlers share one tracker, so mu guards the tasks and the ID generator.
 type TaskTracker struct {
+	store tasks.TaskStore
+
+	mu        sync.Mutex
 	tasks     []Task
 	nextIDGen func() int
 }
 
-// idGenerator is a closure that generates unique sequential integer IDs.
+// idGenerator is a closure that generates unique sequential integer IDs, starting after last.
 // It encapsulates the 'id' counter, so it's not a global variable.
-func idGenerator() func() int {
-	id := 0
+func idGenerator(last int) func() int {
+	id := last
 
 	return func() int {
 		id++
@@ -221,33 +233,57 @@ func idGenerator() func() int {
 	}
 }
 
-// NewTaskTracker creates and initializes a new TaskTracker instance  .
-// It also sets up the unique ID generator.
-func NewTaskTracker() *TaskTracker {
-	return &TaskTracker{
-		tasks:     []Task{},
-		nextIDGen: idGenerator(),
+// NewTaskTracker creates a TaskTracker holding the tasks already in the store.
+// The ID generator continues after the highest ID the store ever saved, deleted tasks
+// included, so IDs are not reused after a restart.
+func NewTaskTracker(store tasks.TaskStore) (*TaskTracker, error) {
+	stored, err := store.Load()
+	if err != nil {
+		return nil, fmt.Errorf("loading tasks: %w", err)
+	}
+
+	lastID, err := store.LastID()
+	if err != nil {
+		return nil, fmt.Errorf("loading tasks: %w", err)
 	}
+
+	return &TaskTracker{
+		store:     store,
+		tasks:     append([]Task{}, stored...),
+		nextIDGen: idGenerator(lastID),
+	}, nil
 }
 
 // AddTask adds a new task to the tracker and returns the added Task.
 // It uses a pointer receiver (*TaskTracker) because it modifies the TaskTracker's state (its 'tasks' slice).
-func (tt *TaskTracker) AddTask(description string) Task {
+func (tt *TaskTracker) AddTask(description string) (Task, error) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
 	newID := tt.nextIDGen()
 	newTask := Task{
 		ID:          newID,
 		Description: description,
 		Completed:   false,
 	}
+
+	err := tt.store.Save(newTask)
+	if err != nil {
+		return Task{}, fmt.Errorf("saving task: %w", err)
+	}
+
 	tt.tasks = append(tt.tasks, newTask)
 
-	return newTask
+	return newTask, nil
 }
 
 // ListTasks displays all pending tasks.
 // It uses a pointer receiver (*TaskTracker) because it operates on the TaskTracker's 'tasks' slice,
 // even though it doesn't modify it directly in this function (good practice for methods operating on collections).
 func (tt *TaskTracker) ListTasks() string {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
 	s := "Pending Tasks:\n"
 	foundPending := false
 
@@ -267,25 +303,109 @@ func (tt *TaskTracker) ListTasks() string {
 
 // CompleteTask marks a task as completed given its ID.
 // It returns a boolean indicating if the task was found and its completion status was changed,
-// and a string message describing the outcome.
-func (tt *TaskTracker) CompleteTask(id int) (success bool, message string) {
+// and a string message describing the outcome. The error is set when the store could not be updated.
+func (tt *TaskTracker) CompleteTask(id int) (success bool, message string, err error) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
 	for i := range tt.tasks {
-		if tt.tasks[i].ID == id {
-			if tt.tasks[i].Completed {
-				return false, fmt.Sprintf("Task %d is already completed.", id)
-			}
+		if tt.tasks[i].ID != id {
+			continue
+		}
+
+		if tt.tasks[i].Completed {
+			return false, fmt.Sprintf("Task %d is already completed.", id), nil
+		}
 
-			tt.tasks[i].Completed = true
+		completed := tt.tasks[i]
+		completed.Completed = true
 
-			return true, fmt.Sprintf("Marking task %d as completed: %s", id, tt.tasks[i].Description)
+		err = tt.store.Save(completed)
+		if err != nil {
+			return false, fmt.Sprintf("Task %d could not be saved.", id), fmt.Errorf("saving task: %w", err)
+		}
+
+		tt.tasks[i] = completed
+
+		return true, fmt.Sprintf("Marking task %d as completed: %s", id, completed.Description), nil
+	}
+
+	return false, fmt.Sprintf("Task with ID %d not found.", id), nil
+}
+
+// DeleteTask removes a task given its ID, and reports whether it was found.
+func (tt *TaskTracker) DeleteTask(id int) (bool, error) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	for i := range tt.tasks {
+		if tt.tasks[i].ID != id {
+			continue
+		}
+
+		err := tt.store.Delete(id)
+		if err != nil {
+			return false, fmt.Errorf("deleting task: %w", err)
+		}
+
+		tt.tasks = append(tt.tasks[:i], tt.tasks[i+1:]...)
+
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// maxID returns the highest ID among the tasks, the upper bound the handlers check IDs against.
+func (tt *TaskTracker) maxID() int {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	return tasks.MaxID(tt.tasks)
+}
+
+// taskByID returns the task with the ID, and whether there is one.
+func (tt *TaskTracker) taskByID(id int) (Task, bool) {
+	tt.mu.Lock()
+	defer tt.mu.Unlock()
+
+	for _, task := range tt.tasks {
+		if task.ID == id {
+			return task, true
 		}
 	}
 
-	return false, fmt.Sprintf("Task with ID %d not found.", id)
+	return Task{}, false
 }
 
 func main() {
-	tracker := NewTaskTracker()
+	storeSpec := flag.String("store", "json:tasks.json", "where tasks are kept: memory, json:PATH or sqlite:PATH")
+	flag.Parse()
+
+	err := run(*storeSpec)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// run opens the task store and serves the API until the server stops.
+func run(storeSpec string) error {
+	store, err := tasks.Open(storeSpec)
+	if err != nil {
+		return fmt.Errorf("opening task store: %w", err)
+	}
+
+	defer func() {
+		closeErr := store.Close()
+		if closeErr != nil {
+			log.Printf("Closing task store: %v", closeErr)
+		}
+	}()
+
+	tracker, err := NewTaskTracker(store)
+	if err != nil {
+		return err
+	}
 
 	http.HandleFunc("GET /task", func(w http.ResponseWriter, r *http.Request) { httpListtask(w, r, tracker) })
 	http.HandleFunc("GET /task/{id}", func(w http.ResponseWriter, r *http.Request) { httpListbyID(w, r, tracker) })
@@ -301,9 +421,11 @@ func main() {
 	}
 
 	log.Printf("Server starting on port %s", server.Addr)
-	err := server.ListenAndServe()
+	err = server.ListenAndServe()
 
 	if err != nil && !errors.Is(err, http.ErrServerClosed) {
-		log.Fatalf("Server failed to start: %v", err)
+		return fmt.Errorf("server failed to start: %w", err)
 	}
+
+	return nil
 }
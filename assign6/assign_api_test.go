@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+
+	"assignment/tasks"
+)
+
+func openTracker(t *testing.T, path string) *TaskTracker {
+	t.Helper()
+
+	store, err := tasks.OpenSQLiteStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { store.Close() })
+
+	tracker, err := NewTaskTracker(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tracker
+}
+
+func TestTrackerRestartAfterDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tasks.db")
+
+	tracker := openTracker(t, path)
+	for _, description := range []string{"Task A", "Task B", "Task C"} {
+		if _, err := tracker.AddTask(description); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if found, err := tracker.DeleteTask(3); !found || err != nil {
+		t.Fatalf("Expected task 3 to be deleted, got %v, %v", found, err)
+	}
+
+	tracker = openTracker(t, path)
+
+	added, err := tracker.AddTask("Task D")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if added.ID != 4 {
+		t.Errorf("Expected the deleted ID 3 not to be reused, got %d", added.ID)
+	}
+}
+
+func TestParallelPosts(t *testing.T) {
+	tracker := openTracker(t, filepath.Join(t.TempDir(), "tasks.db"))
+
+	const posts = 20
+
+	var wg sync.WaitGroup
+
+	for i := range posts {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/task?task=task"+strconv.Itoa(i), http.NoBody)
+			httppostTask(rec, req, tracker)
+
+			if rec.Code != http.StatusCreated {
+				t.Errorf("POST %d: expected status %d, got %d", i, http.StatusCreated, rec.Code)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	stored, err := tracker.store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stored) != posts {
+		t.Fatalf("Expected %d stored tasks, got %d", posts, len(stored))
+	}
+
+	for i, task := range stored {
+		if task.ID != i+1 {
+			t.Errorf("Expected IDs 1 to %d without gaps or repeats, got %v", posts, stored)
+			break
+		}
+	}
+}
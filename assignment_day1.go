@@ -2,16 +2,13 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
 	"time"
-)
 
-const (
-	USD = 1.0
-	EUR = 0.92
-	INR = 83.12
-	JPY = 157.45
+	"assignment/converter"
 )
 
 func init() {
@@ -24,81 +21,240 @@ func init() {
 		fmt.Println("Good Evening!")
 	}
 }
-func validateInput(args []string) (float64, string, string, error) {
+
+func validateInput(args []string) (converter.Request, error) {
+
+	args, date, err := takeOption(args, "date")
+	if err != nil {
+		return converter.Request{}, err
+	}
+
+	args, locale, err := takeOption(args, "locale")
+	if err != nil {
+		return converter.Request{}, err
+	}
 
 	if len(args) != 4 {
-		return 0, "", "", fmt.Errorf("invalid number of arguments")
+		return converter.Request{}, fmt.Errorf("invalid number of arguments")
 	}
-	amount, err := strconv.ParseFloat(args[1], 64)
 
-	if err != nil || amount <= 0.0 {
-		return 0, "", "", fmt.Errorf("invalid amount, please enter the valid amount")
+	return converter.ValidateRequest(args[1], args[2], args[3], date, locale)
+}
+
+// takeOption removes "--name value" or "--name=value" from args and returns the remaining args and the value.
+func takeOption(args []string, name string) ([]string, string, error) {
+	flag := "--" + name
+	rest := make([]string, 0, len(args))
+	value := ""
+
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == flag:
+			if i+1 >= len(args) {
+				return nil, "", fmt.Errorf("missing value for %s", flag)
+			}
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], flag+"="):
+			value = strings.TrimPrefix(args[i], flag+"=")
+		default:
+			rest = append(rest, args[i])
+		}
 	}
+	return rest, value, nil
+}
 
-	from := args[2]
-	to := args[3]
+func main() {
 
-	if !isSupport(from) {
-		return 0, "", "", fmt.Errorf("unsupported source currency %s", from)
+	args, ratesFile, err := takeOption(os.Args, "rates")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	args, ratesURL, err := takeOption(args, "rates-url")
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	if !isSupport(to) {
-		return 0, "", "", fmt.Errorf("unsupported target currency %s", to)
+	args, history, err := takeOption(args, "history")
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
-	return amount, from, to, nil
+	args, roundName, err := takeOption(args, "round")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	mode, err := converter.ParseRoundingMode(roundName)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-}
+	args, ttlStr, err := takeOption(args, "cache-ttl")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	cacheTTL := converter.DefaultCacheTTL
+	if ttlStr != "" {
+		cacheTTL, err = time.ParseDuration(ttlStr)
+		if err != nil || cacheTTL < 0 {
+			fmt.Printf("invalid cache TTL %s, please use a duration such as 10m\n", ttlStr)
+			return
+		}
+	}
 
-func isSupport(currency string) bool {
-	return currency == "USD" || currency == "EUR" || currency == "INR" || currency == "JPY"
-}
+	rates, err := converter.NewRateProvider(ratesFile, ratesURL, history, cacheTTL)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-func convertCurrency(amount float64, from, to string) (float64, error) {
-	var usdAmount float64
-	switch from {
-	case "USD":
-		usdAmount = amount
-	case "EUR":
-		usdAmount = amount / EUR
-	case "INR":
-		usdAmount = amount / INR
-	case "JPY":
-		usdAmount = amount / JPY
-	default:
-		return 0, fmt.Errorf("unsupported source currency %s", from)
+	args, feesFile, err := takeOption(args, "fees")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var fees converter.FeePolicy
+	if feesFile != "" {
+		fees, err = converter.LoadFeePolicy(feesFile)
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
 	}
 
-	switch to {
-	case "USD":
+	args, auditFile, err := takeOption(args, "audit")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	var audit *converter.AuditLog
+	if auditFile != "" {
+		audit, err = converter.OpenAuditLog(auditFile, converter.RateSourceName(ratesFile, ratesURL, history))
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		defer audit.Close()
+	}
 
-		return usdAmount, nil
-	case "EUR":
+	if len(args) > 1 && args[1] == "verify" {
+		if len(args) != 3 {
+			fmt.Println("Usage: verify <audit.jsonl>")
+			return
+		}
+		if ok := runVerify(os.Stdout, args[2]); !ok {
+			audit.Close()
+			os.Exit(1)
+		}
+		return
+	}
 
-		return usdAmount * EUR, nil
-	case "INR":
+	if len(args) > 1 && args[1] == "snapshot" {
+		args, date, err := takeOption(args, "date")
+		if err != nil || len(args) != 3 {
+			fmt.Println("Usage: snapshot <output.json> [--date YYYY-MM-DD]")
+			return
+		}
+		if err := exportSnapshot(rates, date, args[2]); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
 
-		return usdAmount * INR, nil
-	case "JPY":
-		return usdAmount * JPY, nil
-	default:
-		return 0, fmt.Errorf("unsupported destination currency %s", to)
+	if len(args) > 1 && args[1] == "serve" {
+		args, addr, err := takeOption(args, "addr")
+		if err != nil || len(args) != 2 {
+			fmt.Println("Usage: serve [--addr :8080]")
+			return
+		}
+		if addr == "" {
+			addr = ":8080"
+		}
+		if err := serve(addr, rates, mode, fees, audit); err != nil {
+			fmt.Println(err)
+		}
+		return
+	}
 
+	if len(args) == 1 {
+		session := &replSession{rates: rates, mode: mode, fees: fees, showFees: feesFile != "", audit: audit}
+		if home, err := os.UserHomeDir(); err == nil {
+			session.historyFile = filepath.Join(home, replHistoryName)
+		}
+		session.run(os.Stdin, os.Stdout)
+		return
 	}
-}
 
-func main() {
+	if len(args) > 1 && args[1] == "batch" {
+		if len(args) != 4 {
+			fmt.Println("Usage: batch <input.csv|input.jsonl> <output>")
+			return
+		}
+		summary, err := runBatch(rates, mode, fees, feesFile != "", audit, args[2], args[3])
+		if err != nil {
+			fmt.Println(err)
+			return
+		}
+		fmt.Println(summary)
+		return
+	}
 
-	amount, from, to, err := validateInput(os.Args)
+	req, err := validateInput(args)
 
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	convertedAmount, err := convertCurrency(amount, from, to)
+	result, err := converter.Convert(rates, req, mode, fees)
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
-	fmt.Printf("%.3f %s is equivalent to %.3f %s \n", amount, from, convertedAmount, to)
+	if err := audit.Record(req, result, mode); err != nil {
+		fmt.Println(err)
+		return
+	}
+	printResult(os.Stdout, req, result, mode, feesFile != "")
+}
+
+// printResult writes a conversion for the terminal. With showFees the
+// mid-market amount, spread, fee and net amount are listed separately.
+func printResult(w io.Writer, req converter.Request, result converter.Result, mode converter.RoundingMode, showFees bool) {
+	printWarnings(w, result)
+	fmt.Fprintf(w, "%s is equivalent to %s \n",
+		converter.FormatMoney(req.Amount.Round(mode), req.Locale), converter.FormatMoney(result.Amount, req.Locale))
+	printDetails(w, req, result, showFees)
+}
+
+func printWarnings(w io.Writer, result converter.Result) {
+	for _, warning := range result.Warnings {
+		fmt.Fprintln(w, "Warning:", warning)
+	}
+}
+
+// printDetails writes the fee breakdown, route and rate date that follow a conversion's headline.
+func printDetails(w io.Writer, req converter.Request, result converter.Result, showFees bool) {
+	if showFees {
+		fmt.Fprintf(w, "  Mid-market: %s\n", converter.FormatMoney(result.Amount, req.Locale))
+		fmt.Fprintf(w, "  Spread:     -%s\n", converter.FormatMoney(result.Spread, req.Locale))
+		fmt.Fprintf(w, "  Fee:        -%s\n", converter.FormatMoney(result.Fee, req.Locale))
+		fmt.Fprintf(w, "  Net:        %s\n", converter.FormatMoney(result.Net, req.Locale))
+	}
+	fmt.Fprintf(w, "Path: %s\n", converter.FormatPath(result.Path))
+	if result.Stale {
+		fmt.Fprintln(w, "Rates are stale: served from the cache while the rate source is refreshed")
+	}
+	switch {
+	case result.RateDate.IsZero():
+	case !req.Date.IsZero() && !req.Date.Equal(result.RateDate):
+		fmt.Fprintf(w, "Rates used: %s (no quote on %s)\n", result.RateDate.Format(time.DateOnly), req.Date.Format(time.DateOnly))
+	default:
+		fmt.Fprintf(w, "Rates used: %s\n", result.RateDate.Format(time.DateOnly))
+	}
 }
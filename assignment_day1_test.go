@@ -0,0 +1,69 @@
+package main
+
+import "testing"
+
+func TestValidateInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantFrom string
+		wantTo   string
+		wantErr  string
+	}{
+		{
+			name:     "Registered_Codes",
+			args:     []string{"prog", "10", "GBP", "CHF"},
+			wantFrom: "GBP",
+			wantTo:   "CHF",
+		},
+		{
+			name:     "Lower_Case_Codes",
+			args:     []string{"prog", "10", "usd", "inr"},
+			wantFrom: "USD",
+			wantTo:   "INR",
+		},
+		{
+			name:    "Typo_Suggests_Nearest",
+			args:    []string{"prog", "10", "USS", "EUR"},
+			wantErr: "unsupported source currency USS, did you mean USD?",
+		},
+		{
+			name:    "Unknown_Without_Suggestion",
+			args:    []string{"prog", "10", "USD", "1234"},
+			wantErr: "unsupported target currency 1234",
+		},
+		{
+			name:     "Date_Option",
+			args:     []string{"prog", "--date", "2024-06-12", "10", "EUR", "USD"},
+			wantFrom: "EUR",
+			wantTo:   "USD",
+		},
+		{
+			name:    "Invalid_Date",
+			args:    []string{"prog", "10", "EUR", "USD", "--date=12/06/2024"},
+			wantErr: "invalid date 12/06/2024, please use YYYY-MM-DD",
+		},
+		{
+			name:    "Invalid_Amount",
+			args:    []string{"prog", "-1", "USD", "EUR"},
+			wantErr: "invalid amount, please enter the valid amount",
+		},
+	}
+
+	for _, tt := range tests {
+		req, err := validateInput(tt.args)
+		if tt.wantErr != "" {
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("%s: expected error %q, got %v", tt.name, tt.wantErr, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if req.Amount.Currency.Code != tt.wantFrom || req.To.Code != tt.wantTo {
+			t.Errorf("%s: expected %s->%s, got %s->%s", tt.name, tt.wantFrom, tt.wantTo, req.Amount.Currency.Code, req.To.Code)
+		}
+	}
+}
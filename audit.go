@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"assignment/converter"
+)
+
+// runVerify checks an audit trail and reports any mismatches. It returns false if the trail did not verify.
+func runVerify(w io.Writer, path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return false
+	}
+	defer f.Close()
+
+	checked, mismatches, err := converter.VerifyAudit(f)
+	for _, m := range mismatches {
+		fmt.Fprintln(w, m)
+	}
+	if err != nil {
+		fmt.Fprintln(w, err)
+		return false
+	}
+	fmt.Fprintf(w, "Verified %d conversions, %d mismatched\n", checked, len(mismatches))
+	return len(mismatches) == 0
+}
+
+// exportSnapshot writes the rate table in effect on date, or the latest one, to a JSON file that --rates can read back.
+func exportSnapshot(rates converter.RateProvider, date, outPath string) error {
+	var on time.Time
+	if date != "" {
+		var err error
+		on, err = time.Parse(time.DateOnly, date)
+		if err != nil {
+			return fmt.Errorf("invalid date %s, please use YYYY-MM-DD", date)
+		}
+	}
+
+	table, err := rates.Rates(on)
+	if err != nil {
+		return fmt.Errorf("%w: %w", converter.ErrRateSource, err)
+	}
+	table.Stale = false
+
+	data, err := json.MarshalIndent(table, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outPath, append(data, '\n'), 0o644)
+}
@@ -0,0 +1,342 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"assignment/converter"
+)
+
+// batchRecord is one conversion read from a batch input file.
+type batchRecord struct {
+	Line   int
+	Amount string
+	From   string
+	To     string
+	Date   string
+	Err    error // set when the row could not be read
+
+	rawAmount json.RawMessage // amount as written in a JSON-lines row, echoed back unchanged
+}
+
+// batchRowError reports a row that failed to read or convert.
+type batchRowError struct {
+	Line int
+	Err  error
+}
+
+func (e batchRowError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+// batchSummary is the outcome of runBatch.
+type batchSummary struct {
+	Converted int
+	Failed    []batchRowError
+	Warnings  []string // rate table warnings, each reported once
+}
+
+func (s batchSummary) String() string {
+	var b strings.Builder
+	for _, warning := range s.Warnings {
+		b.WriteString("Warning: " + warning + "\n")
+	}
+	for _, failure := range s.Failed {
+		b.WriteString(failure.Error() + "\n")
+	}
+	fmt.Fprintf(&b, "Converted %d rows, %d failed", s.Converted, len(s.Failed))
+	return b.String()
+}
+
+// batchWriter writes converted rows in the input's format.
+type batchWriter interface {
+	Write(rec batchRecord, result converter.Result) error
+	Close() error
+}
+
+// runBatch converts every row of a CSV or JSON-lines file and writes the results,
+// in the same format, to outPath. Rows that fail are collected in the summary
+// with their line numbers and left out of the output. Each converted row is
+// recorded in the audit log, if there is one. With showFees, set when a fee policy
+// was loaded, each row lists the mid-market amount, spread, fee and net amount.
+// The input is read in full before anything is written, and the output is written
+// to a temporary file that replaces outPath only once every row has been written,
+// so a failed run leaves an existing outPath as it was.
+func runBatch(rates converter.RateProvider, mode converter.RoundingMode, fees converter.FeePolicy, showFees bool, audit *converter.AuditLog, inPath, outPath string) (batchSummary, error) {
+	var (
+		read      func(io.Reader) ([]batchRecord, error)
+		newWriter func(io.Writer) batchWriter
+	)
+	switch ext := strings.ToLower(filepath.Ext(inPath)); ext {
+	case ".csv":
+		read = readCSVBatch
+		newWriter = func(w io.Writer) batchWriter { return newCSVBatchWriter(w, showFees) }
+	case ".jsonl", ".ndjson":
+		read = readJSONBatch
+		newWriter = func(w io.Writer) batchWriter { return newJSONBatchWriter(w, showFees) }
+	default:
+		return batchSummary{}, fmt.Errorf("unsupported batch format %q, use .csv or .jsonl", ext)
+	}
+
+	in, err := os.Open(inPath)
+	if err != nil {
+		return batchSummary{}, fmt.Errorf("opening batch input: %w", err)
+	}
+	defer in.Close()
+
+	if inInfo, err := in.Stat(); err == nil {
+		if outInfo, err := os.Stat(outPath); err == nil && os.SameFile(inInfo, outInfo) {
+			return batchSummary{}, fmt.Errorf("batch output %s is the input file", outPath)
+		}
+	}
+
+	records, err := read(in)
+	if err != nil {
+		return batchSummary{}, fmt.Errorf("reading batch input: %w", err)
+	}
+
+	out, err := os.CreateTemp(filepath.Dir(outPath), filepath.Base(outPath)+".*.tmp")
+	if err != nil {
+		return batchSummary{}, fmt.Errorf("creating batch output: %w", err)
+	}
+	summary, err := convertBatch(rates, mode, fees, audit, records, newWriter(out))
+	if closeErr := out.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("writing batch output: %w", closeErr)
+	}
+	if err == nil {
+		if renameErr := os.Rename(out.Name(), outPath); renameErr != nil {
+			err = fmt.Errorf("writing batch output: %w", renameErr)
+		}
+	}
+	if err != nil {
+		os.Remove(out.Name())
+		return summary, err
+	}
+	return summary, nil
+}
+
+// convertBatch converts the records and writes the converted rows with writer.
+func convertBatch(rates converter.RateProvider, mode converter.RoundingMode, fees converter.FeePolicy, audit *converter.AuditLog, records []batchRecord, writer batchWriter) (batchSummary, error) {
+	var summary batchSummary
+	warned := map[string]bool{}
+	for _, rec := range records {
+		req, result, err := convertRecord(rates, mode, fees, rec)
+		if err != nil {
+			summary.Failed = append(summary.Failed, batchRowError{Line: rec.Line, Err: err})
+			continue
+		}
+		if err := audit.Record(req, result, mode); err != nil {
+			return summary, err
+		}
+		for _, warning := range result.Warnings {
+			if !warned[warning] {
+				warned[warning] = true
+				summary.Warnings = append(summary.Warnings, warning)
+			}
+		}
+		if err := writer.Write(rec, result); err != nil {
+			return summary, fmt.Errorf("writing batch output: %w", err)
+		}
+		summary.Converted++
+	}
+
+	if err := writer.Close(); err != nil {
+		return summary, fmt.Errorf("writing batch output: %w", err)
+	}
+	return summary, nil
+}
+
+// convertRecord validates a row with the same rules as command line input and converts it.
+// The fields are taken as they are, so a field such as "--date" is an invalid value, not an option.
+func convertRecord(rates converter.RateProvider, mode converter.RoundingMode, fees converter.FeePolicy, rec batchRecord) (converter.Request, converter.Result, error) {
+	if rec.Err != nil {
+		return converter.Request{}, converter.Result{}, rec.Err
+	}
+
+	req, err := converter.ValidateRequest(rec.Amount, rec.From, rec.To, rec.Date, "")
+	if err != nil {
+		return converter.Request{}, converter.Result{}, err
+	}
+	result, err := converter.Convert(rates, req, mode, fees)
+	return req, result, err
+}
+
+// readCSVBatch reads "amount,from,to[,date]" rows. A first row naming those columns is a header;
+// any other first row is data, and is reported like the rest when it is invalid.
+func readCSVBatch(r io.Reader) ([]batchRecord, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var records []batchRecord
+	for first := true; ; first = false {
+		fields, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			records = append(records, batchRecord{Line: parseErr.StartLine, Err: parseErr.Err})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first && isBatchHeader(fields) {
+			continue
+		}
+
+		rec := batchRecord{Line: line}
+		switch len(fields) {
+		case 4:
+			rec.Date = fields[3]
+			fallthrough
+		case 3:
+			rec.Amount, rec.From, rec.To = fields[0], fields[1], fields[2]
+		default:
+			rec.Err = fmt.Errorf("expected amount,from,to[,date], got %d fields", len(fields))
+		}
+		records = append(records, rec)
+	}
+}
+
+// isBatchHeader reports whether fields name the amount,from,to[,date] columns.
+func isBatchHeader(fields []string) bool {
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = strings.ToLower(strings.TrimSpace(field))
+	}
+	header := strings.Join(names, ",")
+	return header == "amount,from,to" || header == "amount,from,to,date"
+}
+
+// jsonBatchRow is a JSON-lines batch row. Amounts may be given as numbers or strings.
+// Converted rows carry either the converted amount or, with a fee policy, its breakdown.
+type jsonBatchRow struct {
+	Amount    json.RawMessage `json:"amount"`
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Date      string          `json:"date,omitempty"`
+	Converted string          `json:"converted,omitempty"`
+	MidMarket string          `json:"mid_market,omitempty"`
+	Spread    string          `json:"spread,omitempty"`
+	Fee       string          `json:"fee,omitempty"`
+	Net       string          `json:"net,omitempty"`
+	Rate      string          `json:"rate,omitempty"`
+}
+
+// readJSONBatch reads one JSON object per line, skipping blank lines.
+func readJSONBatch(r io.Reader) ([]batchRecord, error) {
+	scanner := bufio.NewScanner(r)
+
+	var records []batchRecord
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var row jsonBatchRow
+		rec := batchRecord{Line: line}
+		if err := json.Unmarshal([]byte(text), &row); err != nil {
+			rec.Err = fmt.Errorf("invalid JSON: %w", err)
+		} else {
+			rec.From, rec.To, rec.Date, rec.rawAmount = row.From, row.To, row.Date, row.Amount
+			if err := json.Unmarshal(row.Amount, &rec.Amount); err != nil {
+				rec.Amount = string(row.Amount)
+			}
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+type csvBatchWriter struct {
+	w        *csv.Writer
+	header   bool
+	showFees bool
+}
+
+func newCSVBatchWriter(w io.Writer, showFees bool) *csvBatchWriter {
+	return &csvBatchWriter{w: csv.NewWriter(w), showFees: showFees}
+}
+
+func (c *csvBatchWriter) Write(rec batchRecord, result converter.Result) error {
+	if !c.header {
+		c.header = true
+		header := []string{"amount", "from", "to", "date", "converted", "rate"}
+		if c.showFees {
+			header = []string{"amount", "from", "to", "date", "mid_market", "spread", "fee", "net", "rate"}
+		}
+		if err := c.w.Write(header); err != nil {
+			return err
+		}
+	}
+
+	row := []string{rec.Amount, rec.From, rec.To, rateDateColumn(rec, result), formatAmount(result.Amount)}
+	if c.showFees {
+		row = append(row, formatAmount(result.Spread), formatAmount(result.Fee), formatAmount(result.Net))
+	}
+	return c.w.Write(append(row, result.Rate.FloatString(converter.RateDecimals)))
+}
+
+func (c *csvBatchWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonBatchWriter struct {
+	enc      *json.Encoder
+	showFees bool
+}
+
+func newJSONBatchWriter(w io.Writer, showFees bool) *jsonBatchWriter {
+	return &jsonBatchWriter{enc: json.NewEncoder(w), showFees: showFees}
+}
+
+func (j *jsonBatchWriter) Write(rec batchRecord, result converter.Result) error {
+	row := jsonBatchRow{
+		Amount: rec.rawAmount,
+		From:   rec.From,
+		To:     rec.To,
+		Date:   rateDateColumn(rec, result),
+		Rate:   result.Rate.FloatString(converter.RateDecimals),
+	}
+	if j.showFees {
+		row.MidMarket = formatAmount(result.Amount)
+		row.Spread = formatAmount(result.Spread)
+		row.Fee = formatAmount(result.Fee)
+		row.Net = formatAmount(result.Net)
+	} else {
+		row.Converted = formatAmount(result.Amount)
+	}
+	return j.enc.Encode(row)
+}
+
+func (j *jsonBatchWriter) Close() error {
+	return nil
+}
+
+// formatAmount writes an amount with its currency's minor units and no symbol, for output files.
+func formatAmount(m converter.Money) string {
+	return m.Amount.FloatString(m.Currency.MinorUnits)
+}
+
+// rateDateColumn keeps the row's own date, or fills in the date of the rates used when the row had none.
+func rateDateColumn(rec batchRecord, result converter.Result) string {
+	if rec.Date != "" || result.RateDate.IsZero() {
+		return rec.Date
+	}
+	return result.RateDate.Format(time.DateOnly)
+}
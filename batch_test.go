@@ -0,0 +1,170 @@
+package main
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"assignment/converter"
+)
+
+func TestRunBatchReportsBadRows(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.csv")
+	out := filepath.Join(dir, "out.csv")
+	input := "amount,from,to\n100,USD,EUR\nabc,USD,EUR\n10,USD,JPY\n5,USD\n"
+	if err := os.WriteFile(in, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := runBatch(converter.DefaultRates(), converter.RoundHalfEven, converter.FeePolicy{}, false, nil, in, out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if summary.Converted != 2 {
+		t.Errorf("Expected 2 converted rows, got %d", summary.Converted)
+	}
+	if len(summary.Failed) != 2 || summary.Failed[0].Line != 3 || summary.Failed[1].Line != 5 {
+		t.Errorf("Expected failures on lines 3 and 5, got %v", summary.Failed)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "amount,from,to,date,converted,rate\n100,USD,EUR,,92.00,0.920000\n10,USD,JPY,,1574,157.450000\n"
+	if string(got) != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunBatchJSONLines(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.jsonl")
+	out := filepath.Join(dir, "out.jsonl")
+	input := "{\"amount\": 100, \"from\": \"USD\", \"to\": \"INR\"}\n\n{broken\n"
+	if err := os.WriteFile(in, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := runBatch(converter.DefaultRates(), converter.RoundHalfEven, converter.FeePolicy{}, false, nil, in, out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Converted != 1 || len(summary.Failed) != 1 || summary.Failed[0].Line != 3 {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "{\"amount\":100,\"from\":\"USD\",\"to\":\"INR\",\"converted\":\"8312.00\",\"rate\":\"83.120000\"}\n"
+	if string(got) != want {
+		t.Errorf("Unexpected output:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestRunBatchBrokenQuote(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.csv")
+	out := filepath.Join(dir, "out.csv")
+	input := "amount,from,to\n100,USD,EUR\n1\"0,USD,EUR\n10,USD,JPY\n"
+	if err := os.WriteFile(in, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := runBatch(converter.DefaultRates(), converter.RoundHalfEven, converter.FeePolicy{}, false, nil, in, out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Converted != 2 || len(summary.Failed) != 1 || summary.Failed[0].Line != 3 {
+		t.Errorf("Expected the row on line 3 to fail and the others to convert, got %s", summary)
+	}
+}
+
+func TestRunBatchAppliesFees(t *testing.T) {
+	fees := converter.FeePolicy{
+		Spreads: map[string]*big.Rat{"USD/INR": big.NewRat(3, 2)},
+		Fees:    map[string]converter.FeeRule{"USD": {Flat: big.NewRat(1, 1)}},
+	}
+	tests := []struct {
+		name, input, want string
+	}{
+		{"in.csv", "100,USD,INR\n", "amount,from,to,date,mid_market,spread,fee,net,rate\n100,USD,INR,,8312.00,124.68,83.12,8104.20,83.120000\n"},
+		{"in.jsonl", "{\"amount\": 100, \"from\": \"USD\", \"to\": \"INR\"}\n",
+			"{\"amount\":100,\"from\":\"USD\",\"to\":\"INR\",\"mid_market\":\"8312.00\",\"spread\":\"124.68\",\"fee\":\"83.12\",\"net\":\"8104.20\",\"rate\":\"83.120000\"}\n"},
+	}
+
+	dir := t.TempDir()
+	for _, tt := range tests {
+		in := filepath.Join(dir, tt.name)
+		out := filepath.Join(dir, "out-"+tt.name)
+		if err := os.WriteFile(in, []byte(tt.input), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		summary, err := runBatch(converter.DefaultRates(), converter.RoundHalfEven, fees, true, nil, in, out)
+		if err != nil || summary.Converted != 1 {
+			t.Fatalf("%s: unexpected result %s, %v", tt.name, summary, err)
+		}
+		got, err := os.ReadFile(out)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("%s: unexpected output:\n%s\nwant:\n%s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRunBatchFirstRowIsData(t *testing.T) {
+	dir := t.TempDir()
+	in := filepath.Join(dir, "in.csv")
+	out := filepath.Join(dir, "out.csv")
+	input := "abc,USD,EUR\n100,USD,EUR,--date\n10,--date,EUR\n10,USD,JPY\n"
+	if err := os.WriteFile(in, []byte(input), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	summary, err := runBatch(converter.DefaultRates(), converter.RoundHalfEven, converter.FeePolicy{}, false, nil, in, out)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary.Converted != 1 || len(summary.Failed) != 3 {
+		t.Fatalf("Expected the first three rows to fail and the last to convert, got %s", summary)
+	}
+	for i, failure := range summary.Failed {
+		if failure.Line != i+1 {
+			t.Errorf("Expected failures on lines 1 to 3, got %s", summary)
+			break
+		}
+	}
+}
+
+func TestRunBatchKeepsOutputOnError(t *testing.T) {
+	dir := t.TempDir()
+	out := filepath.Join(dir, "out.csv")
+	if err := os.WriteFile(out, []byte("previous results\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	txt := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(txt, []byte("100,USD,EUR\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, in := range []string{txt, filepath.Join(dir, "missing.csv"), out} {
+		if _, err := runBatch(converter.DefaultRates(), converter.RoundHalfEven, converter.FeePolicy{}, false, nil, in, out); err == nil {
+			t.Errorf("%s: expected an error", filepath.Base(in))
+		}
+		if got, _ := os.ReadFile(out); string(got) != "previous results\n" {
+			t.Errorf("%s: expected the output to be left as it was, got %q", filepath.Base(in), got)
+		}
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected no temporary files to be left, got %v", entries)
+	}
+}
@@ -0,0 +1,291 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AuditRecord is the audit trail line written for one conversion. Every amount is
+// written exactly, and Snapshot names the snapshot line holding the rate table used.
+type AuditRecord struct {
+	Kind       string    `json:"kind"` // always "conversion"
+	Recorded   time.Time `json:"recorded"`
+	Amount     string    `json:"amount"`
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Date       string    `json:"date,omitempty"` // requested rate date, empty for the latest rates
+	Converted  string    `json:"converted"`
+	Spread     string    `json:"spread"`
+	Fee        string    `json:"fee"`
+	Net        string    `json:"net"`
+	Rate       string    `json:"rate"`
+	RateSource string    `json:"rate_source"`
+	RateDate   string    `json:"rate_date,omitempty"`
+	Snapshot   string    `json:"snapshot"`
+	Rounding   string    `json:"rounding"`
+	Path       []string  `json:"path"`
+}
+
+// auditSnapshot is the audit trail line holding a rate table. Each table is written
+// once, before the first conversion that uses it.
+type auditSnapshot struct {
+	Kind     string          `json:"kind"` // always "snapshot"
+	ID       string          `json:"id"`
+	Recorded time.Time       `json:"recorded"`
+	Source   string          `json:"source"`
+	Table    json.RawMessage `json:"table"`
+}
+
+// AuditLog appends audit records to a JSON-lines file. A nil *AuditLog records nothing,
+// so callers can pass one around whether or not auditing was asked for.
+type AuditLog struct {
+	source string
+	now    func() time.Time
+
+	mu        sync.Mutex
+	f         *os.File
+	snapshots map[string]bool // snapshot IDs already in the file
+}
+
+// OpenAuditLog opens, or creates, the audit trail at path for appending.
+// source describes where the rates come from, as given by RateSourceName.
+func OpenAuditLog(path, source string) (*AuditLog, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log: %w", err)
+	}
+
+	log := &AuditLog{source: source, now: time.Now, f: f, snapshots: map[string]bool{}}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxAuditLine)
+	for scanner.Scan() {
+		var head auditSnapshot
+		if json.Unmarshal(scanner.Bytes(), &head) == nil && head.Kind == "snapshot" {
+			log.snapshots[head.ID] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("reading audit log: %w", err)
+	}
+	return log, nil
+}
+
+// maxAuditLine bounds a single line of the audit trail; snapshots of full rate tables are the longest.
+const maxAuditLine = 4 << 20
+
+// Record appends the audit record of a conversion, preceded by a snapshot of its rate table
+// if that table has not been recorded yet.
+func (a *AuditLog) Record(req Request, result Result, mode RoundingMode) error {
+	if a == nil {
+		return nil
+	}
+
+	table := result.Table
+	table.Stale = false
+	tableJSON, err := json.Marshal(table)
+	if err != nil {
+		return fmt.Errorf("writing audit record: %w", err)
+	}
+	sum := sha256.Sum256(tableJSON)
+	id := hex.EncodeToString(sum[:8])
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now().UTC()
+	if !a.snapshots[id] {
+		snapshot := auditSnapshot{Kind: "snapshot", ID: id, Recorded: now, Source: a.source, Table: tableJSON}
+		if err := a.append(snapshot); err != nil {
+			return err
+		}
+		a.snapshots[id] = true
+	}
+
+	rec := AuditRecord{
+		Kind:       "conversion",
+		Recorded:   now,
+		Amount:     exactDecimal(req.Amount.Amount),
+		From:       req.Amount.Currency.Code,
+		To:         req.To.Code,
+		Converted:  result.Amount.Amount.FloatString(result.Amount.Currency.MinorUnits),
+		Spread:     result.Spread.Amount.FloatString(result.Spread.Currency.MinorUnits),
+		Fee:        result.Fee.Amount.FloatString(result.Fee.Currency.MinorUnits),
+		Net:        result.Net.Amount.FloatString(result.Net.Currency.MinorUnits),
+		Rate:       result.Rate.FloatString(RateDecimals),
+		RateSource: a.source,
+		Snapshot:   id,
+		Rounding:   mode.String(),
+		Path:       result.Path,
+	}
+	if !req.Date.IsZero() {
+		rec.Date = req.Date.Format(time.DateOnly)
+	}
+	if !result.RateDate.IsZero() {
+		rec.RateDate = result.RateDate.Format(time.DateOnly)
+	}
+	return a.append(rec)
+}
+
+// append writes one line with a single write, so records are never interleaved.
+func (a *AuditLog) append(line any) error {
+	data, err := json.Marshal(line)
+	if err != nil {
+		return fmt.Errorf("writing audit record: %w", err)
+	}
+	if _, err := a.f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing audit record: %w", err)
+	}
+	return nil
+}
+
+// Close closes the audit trail file.
+func (a *AuditLog) Close() error {
+	if a == nil {
+		return nil
+	}
+	return a.f.Close()
+}
+
+// exactDecimal writes x in full when it is a terminating decimal of reasonable length,
+// as every parsed amount is, and as a fraction otherwise.
+func exactDecimal(x *big.Rat) string {
+	pow, ten := big.NewInt(1), big.NewInt(10)
+	for digits := 0; digits <= 40; digits++ {
+		if new(big.Int).Rem(pow, x.Denom()).Sign() == 0 {
+			return x.FloatString(digits)
+		}
+		pow.Mul(pow, ten)
+	}
+	return x.RatString()
+}
+
+// AuditMismatch is a line of the audit trail that does not match its recomputation.
+type AuditMismatch struct {
+	Line   int
+	Reason string
+}
+
+func (m AuditMismatch) String() string {
+	return fmt.Sprintf("line %d: %s", m.Line, m.Reason)
+}
+
+// VerifyAudit recomputes every conversion in an audit trail from the rate snapshot it
+// names, with the same rounding, and reports the records that no longer match. The fee
+// policy is not part of the trail, so spreads and fees are only checked to add up to the
+// converted amount. It returns the number of conversion records checked.
+func VerifyAudit(r io.Reader) (int, []AuditMismatch, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxAuditLine)
+
+	snapshots := map[string]RateTable{}
+	var (
+		checked    int
+		mismatches []AuditMismatch
+	)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		var snapshot auditSnapshot
+		if err := json.Unmarshal(text, &snapshot); err != nil {
+			mismatches = append(mismatches, AuditMismatch{Line: line, Reason: "invalid JSON: " + err.Error()})
+			continue
+		}
+
+		switch snapshot.Kind {
+		case "snapshot":
+			if sum := sha256.Sum256(snapshot.Table); hex.EncodeToString(sum[:8]) != snapshot.ID {
+				mismatches = append(mismatches, AuditMismatch{Line: line, Reason: fmt.Sprintf("snapshot %s does not match its rate table", snapshot.ID)})
+				continue
+			}
+			table, err := decodeJSONRates(bytes.NewReader(snapshot.Table))
+			if err != nil {
+				mismatches = append(mismatches, AuditMismatch{Line: line, Reason: err.Error()})
+				continue
+			}
+			snapshots[snapshot.ID] = table
+		case "conversion":
+			var rec AuditRecord
+			if err := json.Unmarshal(text, &rec); err != nil {
+				mismatches = append(mismatches, AuditMismatch{Line: line, Reason: "invalid JSON: " + err.Error()})
+				continue
+			}
+			checked++
+			if problems := verifyRecord(rec, snapshots); len(problems) > 0 {
+				mismatches = append(mismatches, AuditMismatch{Line: line, Reason: strings.Join(problems, "; ")})
+			}
+		default:
+			mismatches = append(mismatches, AuditMismatch{Line: line, Reason: fmt.Sprintf("unknown record kind %q", snapshot.Kind)})
+		}
+	}
+	return checked, mismatches, scanner.Err()
+}
+
+// verifyRecord recomputes one conversion and lists every way it differs from the record.
+func verifyRecord(rec AuditRecord, snapshots map[string]RateTable) []string {
+	table, ok := snapshots[rec.Snapshot]
+	if !ok {
+		return []string{fmt.Sprintf("snapshot %s is not in the audit trail", rec.Snapshot)}
+	}
+
+	amount, ok := ParseDecimal(rec.Amount)
+	if !ok {
+		return []string{fmt.Sprintf("invalid amount %q", rec.Amount)}
+	}
+	from, fromOK := LookupCurrency(rec.From)
+	to, toOK := LookupCurrency(rec.To)
+	if !fromOK || !toOK {
+		return []string{fmt.Sprintf("unknown currency pair %s/%s", rec.From, rec.To)}
+	}
+	mode, err := ParseRoundingMode(rec.Rounding)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	req := Request{Amount: Money{Amount: amount, Currency: from}, To: to}
+	result, err := Convert(fixedProvider{table: table}, req, mode, FeePolicy{})
+	if err != nil {
+		return []string{"recomputing: " + err.Error()}
+	}
+
+	var problems []string
+	check := func(field, recorded, recomputed string) {
+		if recorded != recomputed {
+			problems = append(problems, fmt.Sprintf("%s %s, recomputed %s", field, recorded, recomputed))
+		}
+	}
+	check("converted", rec.Converted, result.Amount.Amount.FloatString(to.MinorUnits))
+	check("rate", rec.Rate, result.Rate.FloatString(RateDecimals))
+	check("path", FormatPath(rec.Path), FormatPath(result.Path))
+	rateDate := ""
+	if !result.RateDate.IsZero() {
+		rateDate = result.RateDate.Format(time.DateOnly)
+	}
+	check("rate date", rec.RateDate, rateDate)
+
+	parts := make([]*big.Rat, 4)
+	for i, s := range []string{rec.Converted, rec.Spread, rec.Fee, rec.Net} {
+		if parts[i], ok = ParseDecimal(s); !ok {
+			return append(problems, fmt.Sprintf("invalid amount %q", s))
+		}
+	}
+	net := new(big.Rat).Sub(parts[0], parts[1])
+	net.Sub(net, parts[2])
+	if net.Cmp(parts[3]) != 0 {
+		problems = append(problems, fmt.Sprintf("net %s is not converted %s less spread %s and fee %s", rec.Net, rec.Converted, rec.Spread, rec.Fee))
+	}
+	return problems
+}
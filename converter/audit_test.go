@@ -0,0 +1,94 @@
+package converter
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAuditTrail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	record := func(amount string, from, to string) {
+		t.Helper()
+		log, err := OpenAuditLog(path, "built-in")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer log.Close()
+
+		req, err := ValidateRequest(amount, from, to, "", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := Convert(DefaultRates(), req, RoundHalfUp, FeePolicy{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := log.Record(req, result, RoundHalfUp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record("100.125", "USD", "EUR")
+	record("250", "EUR", "INR")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trail := string(data)
+	if got := strings.Count(trail, `"kind":"snapshot"`); got != 1 {
+		t.Errorf("Expected the shared rate table to be written once, got %d snapshots", got)
+	}
+	for _, want := range []string{`"amount":"100.125"`, `"converted":"92.12"`, `"rounding":"half-up"`, `"path":["EUR","USD","INR"]`, `"rate_source":"built-in"`} {
+		if !strings.Contains(trail, want) {
+			t.Errorf("Expected the audit trail to contain %s, got:\n%s", want, trail)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		trail      string
+		wantReason string
+	}{
+		{"Unchanged", trail, ""},
+		{"Edited_Output", strings.Replace(trail, `"converted":"92.12"`, `"converted":"92.20"`, 1), "line 2: converted 92.20, recomputed 92.12"},
+		{"Edited_Snapshot", strings.Replace(trail, `"EUR":0.92`, `"EUR":0.93`, 1), "line 1: snapshot"},
+		{"Edited_Rounding", strings.Replace(trail, `"rounding":"half-up"`, `"rounding":"truncate"`, 1), "line 2: converted 92.12, recomputed 92.11"},
+	}
+
+	for _, tt := range tests {
+		checked, mismatches, err := VerifyAudit(strings.NewReader(tt.trail))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if checked != 2 {
+			t.Errorf("%s: expected 2 conversions checked, got %d", tt.name, checked)
+		}
+		switch {
+		case tt.wantReason == "" && len(mismatches) > 0:
+			t.Errorf("%s: expected no mismatches, got %v", tt.name, mismatches)
+		case tt.wantReason != "" && (len(mismatches) == 0 || !strings.HasPrefix(mismatches[0].String(), tt.wantReason)):
+			t.Errorf("%s: expected a mismatch starting %q, got %v", tt.name, tt.wantReason, mismatches)
+		}
+	}
+}
+
+func TestExactDecimal(t *testing.T) {
+	tests := []struct {
+		in   *big.Rat
+		want string
+	}{
+		{big.NewRat(100, 1), "100"},
+		{big.NewRat(100125, 1000), "100.125"},
+		{big.NewRat(1, 3), "1/3"},
+	}
+
+	for _, tt := range tests {
+		if got := exactDecimal(tt.in); got != tt.want {
+			t.Errorf("exactDecimal(%s) = %s, want %s", tt.in.RatString(), got, tt.want)
+		}
+	}
+}
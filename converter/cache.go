@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long fetched rates are used before they are refreshed.
+const DefaultCacheTTL = 10 * time.Minute
+
+// CacheStats counts how a CachingProvider answered its callers.
+type CacheStats struct {
+	Hits        int // answered from the cache, fresh or stale
+	Misses      int // had to wait for a fetch
+	StaleHits   int // answered with an expired table
+	Fetches     int // calls made to the wrapped provider
+	FetchErrors int // fetches that failed
+}
+
+// CachingProvider keeps the tables returned by another provider for TTL.
+//
+// Once an entry expires it is still served, marked Stale, while a single background
+// fetch refreshes it. If that fetch fails the old table keeps being served as stale
+// until a later refresh succeeds. Callers asking for a table that is not cached at
+// all wait for one shared fetch instead of each calling the wrapped provider.
+type CachingProvider struct {
+	Source RateProvider
+	TTL    time.Duration
+
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	stats   CacheStats
+}
+
+type cacheEntry struct {
+	table    RateTable
+	fetched  time.Time
+	ok       bool       // table holds a successfully fetched value
+	inflight *cacheCall // fetch in progress, if any
+}
+
+// cacheCall is a fetch shared by every caller that asked for the same entry while it ran.
+type cacheCall struct {
+	done  chan struct{}
+	table RateTable
+	err   error
+}
+
+// NewCachingProvider wraps source in a cache whose entries live for ttl.
+func NewCachingProvider(source RateProvider, ttl time.Duration) *CachingProvider {
+	return &CachingProvider{Source: source, TTL: ttl, now: time.Now}
+}
+
+// Rates returns the cached table for the date, fetching it from Source when needed.
+func (c *CachingProvider) Rates(on time.Time) (RateTable, error) {
+	key := "latest"
+	if !on.IsZero() {
+		key = on.Format(time.DateOnly)
+	}
+
+	c.mu.Lock()
+	if c.entries == nil {
+		c.entries = map[string]*cacheEntry{}
+	}
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &cacheEntry{}
+		c.entries[key] = entry
+	}
+
+	if entry.ok {
+		c.stats.Hits++
+		table := entry.table
+		if c.clock().Sub(entry.fetched) >= c.TTL {
+			c.stats.StaleHits++
+			table.Stale = true
+			if entry.inflight == nil {
+				entry.inflight = c.fetch(entry, on)
+			}
+		}
+		c.mu.Unlock()
+		return table, nil
+	}
+
+	c.stats.Misses++
+	if entry.inflight == nil {
+		entry.inflight = c.fetch(entry, on)
+	}
+	call := entry.inflight
+	c.mu.Unlock()
+
+	<-call.done
+	return call.table, call.err
+}
+
+// Stats returns a snapshot of the cache counters.
+func (c *CachingProvider) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// fetch starts a background call to Source that fills entry. c.mu must be held.
+func (c *CachingProvider) fetch(entry *cacheEntry, on time.Time) *cacheCall {
+	c.stats.Fetches++
+	call := &cacheCall{done: make(chan struct{})}
+
+	go func() {
+		table, err := c.Source.Rates(on)
+
+		c.mu.Lock()
+		if err == nil {
+			entry.table, entry.fetched, entry.ok = table, c.clock(), true
+		} else {
+			c.stats.FetchErrors++
+		}
+		entry.inflight = nil
+		c.mu.Unlock()
+
+		call.table, call.err = table, err
+		close(call.done)
+	}()
+
+	return call
+}
+
+func (c *CachingProvider) clock() time.Time {
+	if c.now == nil {
+		return time.Now()
+	}
+	return c.now()
+}
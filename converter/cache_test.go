@@ -0,0 +1,147 @@
+package converter
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeProvider counts its calls and can be told to fail or to block until released.
+type fakeProvider struct {
+	calls   atomic.Int32
+	fail    atomic.Bool
+	release chan struct{}
+}
+
+func (f *fakeProvider) Rates(_ time.Time) (RateTable, error) {
+	f.calls.Add(1)
+	if f.release != nil {
+		<-f.release
+	}
+	if f.fail.Load() {
+		return RateTable{}, errors.New("feed unavailable")
+	}
+	return RateTable{Base: "USD", Rates: map[string]float64{"EUR": 0.92}}, nil
+}
+
+// wait blocks until no fetch is running, so tests can observe background refreshes.
+func (c *CachingProvider) wait() {
+	for {
+		c.mu.Lock()
+		var pending *cacheCall
+		for _, entry := range c.entries {
+			if entry.inflight != nil {
+				pending = entry.inflight
+				break
+			}
+		}
+		c.mu.Unlock()
+
+		if pending == nil {
+			return
+		}
+		<-pending.done
+	}
+}
+
+func TestCachingProviderTTLAndStale(t *testing.T) {
+	source := &fakeProvider{}
+	cache := NewCachingProvider(source, time.Minute)
+	now := time.Date(2024, 6, 12, 9, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		table, err := cache.Rates(time.Time{})
+		if err != nil || table.Stale {
+			t.Fatalf("Expected fresh rates, got stale=%v err=%v", table.Stale, err)
+		}
+	}
+	if got := cache.Stats(); got != (CacheStats{Hits: 2, Misses: 1, Fetches: 1}) {
+		t.Errorf("Unexpected stats after fresh lookups: %+v", got)
+	}
+
+	// Past the TTL with a failing source: the old table is served, marked stale.
+	now = now.Add(2 * time.Minute)
+	source.fail.Store(true)
+	table, err := cache.Rates(time.Time{})
+	if err != nil || !table.Stale {
+		t.Fatalf("Expected stale rates, got stale=%v err=%v", table.Stale, err)
+	}
+	cache.wait()
+
+	table, err = cache.Rates(time.Time{})
+	if err != nil || !table.Stale || table.Rates["EUR"] != 0.92 {
+		t.Fatalf("Expected stale rates after a failed refresh, got %+v err=%v", table, err)
+	}
+	cache.wait()
+
+	// Once the source recovers the refreshed table is fresh again.
+	source.fail.Store(false)
+	_, _ = cache.Rates(time.Time{})
+	cache.wait()
+	table, err = cache.Rates(time.Time{})
+	if err != nil || table.Stale {
+		t.Fatalf("Expected fresh rates after recovery, got stale=%v err=%v", table.Stale, err)
+	}
+
+	want := CacheStats{Hits: 6, Misses: 1, StaleHits: 3, Fetches: 4, FetchErrors: 2}
+	if got := cache.Stats(); got != want {
+		t.Errorf("Expected stats %+v, got %+v", want, got)
+	}
+}
+
+func TestCachingProviderSharesOneFetch(t *testing.T) {
+	source := &fakeProvider{release: make(chan struct{})}
+	cache := NewCachingProvider(source, time.Minute)
+
+	const callers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := cache.Rates(time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC))
+			errs <- err
+		}()
+	}
+
+	// Let every caller reach the cache before the single fetch completes.
+	for cache.Stats().Misses < callers {
+		time.Sleep(time.Millisecond)
+	}
+	close(source.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if got := source.calls.Load(); got != 1 {
+		t.Errorf("Expected one fetch from the source, got %d", got)
+	}
+	if got := cache.Stats(); got.Fetches != 1 || got.Misses != callers {
+		t.Errorf("Unexpected stats: %+v", got)
+	}
+}
+
+func TestCachingProviderDoesNotCacheErrors(t *testing.T) {
+	source := &fakeProvider{}
+	source.fail.Store(true)
+	cache := NewCachingProvider(source, time.Minute)
+
+	if _, err := cache.Rates(time.Time{}); err == nil {
+		t.Fatal("Expected the fetch error to reach the caller")
+	}
+	source.fail.Store(false)
+	if _, err := cache.Rates(time.Time{}); err != nil {
+		t.Errorf("Expected a new fetch to succeed, got %v", err)
+	}
+	if got := source.calls.Load(); got != 2 {
+		t.Errorf("Expected two fetches, got %d", got)
+	}
+}
@@ -0,0 +1,140 @@
+// Package converter holds the currency conversion core shared by the command line tool and the HTTP server:
+// the ISO 4217 registry, exact money arithmetic, rate providers and the rate graph used to route conversions.
+package converter
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// ErrRateSource wraps failures to load rates from the configured provider.
+var ErrRateSource = errors.New("loading exchange rates")
+
+// Request is a validated conversion.
+type Request struct {
+	Amount Money
+	To     Currency
+	Date   time.Time // zero for the latest rates
+	Locale *Locale   // locale given for parsing and printing, nil to use each currency's own
+}
+
+// Result is the outcome of Convert. Amount, Spread, Fee and Net are all in the
+// target currency and rounded to its minor units, with Net = Amount - Spread - Fee.
+type Result struct {
+	Amount   Money     // converted at the mid-market rate
+	Spread   Money     // markup over the mid-market rate
+	Fee      Money     // flat fee after the minimum and maximum caps
+	Net      Money     // what is received after the spread and fee
+	Rate     *big.Rat  // units of the target currency per unit of the source
+	RateDate time.Time // date of the rate table that was used, zero when undated
+	Path     []string  // currencies the conversion went through, including both ends
+	Warnings []string  // inconsistencies found in the rate table
+	Stale    bool      // the rates came from an expired cache entry
+	Table    RateTable // the rate table the conversion was computed from
+}
+
+// ValidateRequest checks the textual parts of a conversion and builds a Request from them.
+// date may be empty to ask for the latest rates, and locale empty to detect the amount's separators.
+func ValidateRequest(amountStr, fromCode, toCode, date, locale string) (Request, error) {
+	var loc *Locale
+	if locale != "" {
+		l, ok := LookupLocale(locale)
+		if !ok {
+			return Request{}, fmt.Errorf("unsupported locale %s", locale)
+		}
+		loc = &l
+	}
+
+	amount, ok := ParseAmount(amountStr, loc)
+
+	if !ok || amount.Sign() <= 0 {
+		return Request{}, fmt.Errorf("invalid amount, please enter the valid amount")
+	}
+
+	from, err := validateCurrency(fromCode, "source")
+	if err != nil {
+		return Request{}, err
+	}
+
+	to, err := validateCurrency(toCode, "target")
+	if err != nil {
+		return Request{}, err
+	}
+
+	req := Request{Amount: Money{Amount: amount, Currency: from}, To: to, Locale: loc}
+	if date != "" {
+		req.Date, err = time.Parse(time.DateOnly, date)
+		if err != nil {
+			return Request{}, fmt.Errorf("invalid date %s, please use YYYY-MM-DD", date)
+		}
+	}
+
+	return req, nil
+}
+
+// validateCurrency checks code against the currency registry, ignoring case.
+// For unknown codes the error suggests the nearest registered code, if one is close enough.
+func validateCurrency(code, role string) (Currency, error) {
+	currency, ok := LookupCurrency(code)
+	if ok {
+		return currency, nil
+	}
+
+	if suggestion := SuggestCurrency(code); suggestion != "" {
+		return Currency{}, fmt.Errorf("unsupported %s currency %s, did you mean %s?", role, code, suggestion)
+	}
+	return Currency{}, fmt.Errorf("unsupported %s currency %s", role, code)
+}
+
+// Convert converts the requested amount using the rates in effect on the request date
+// and applies the fee policy. The arithmetic is exact; only the amounts in the result
+// are rounded, to the target's minor units.
+func Convert(rates RateProvider, req Request, mode RoundingMode, fees FeePolicy) (Result, error) {
+	table, err := rates.Rates(req.Date)
+	if err != nil {
+		return Result{}, fmt.Errorf("%w: %w", ErrRateSource, err)
+	}
+
+	graph := newRateGraph(table)
+	path, rate, err := graph.route(req.Amount.Currency.Code, req.To.Code)
+	if err != nil {
+		return Result{}, err
+	}
+
+	inTarget := func(x *big.Rat) Money {
+		return Money{Amount: new(big.Rat).Mul(x, rate), Currency: req.To}.Round(mode)
+	}
+
+	spreadCharge, feeCharge := fees.charges(req.Amount, req.To)
+	amount, spread, fee := inTarget(req.Amount.Amount), inTarget(spreadCharge), inTarget(feeCharge)
+
+	net := new(big.Rat).Sub(amount.Amount, spread.Amount)
+	net.Sub(net, fee.Amount)
+	if net.Sign() < 0 {
+		return Result{}, fmt.Errorf("fees of %s exceed the converted amount %s", fee, amount)
+	}
+
+	return Result{
+		Amount:   amount,
+		Spread:   spread,
+		Fee:      fee,
+		Net:      Money{Amount: net, Currency: req.To},
+		Rate:     rate,
+		RateDate: table.Date,
+		Path:     path,
+		Warnings: graph.arbitrageWarnings(),
+		Stale:    table.Stale,
+		Table:    table,
+	}, nil
+}
+
+// ConvertExact converts amount into the target currency using table, without any rounding.
+func ConvertExact(table RateTable, amount Money, to Currency) (Money, error) {
+	_, rate, err := newRateGraph(table).route(amount.Currency.Code, to.Code)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: new(big.Rat).Mul(amount.Amount, rate), Currency: to}, nil
+}
@@ -0,0 +1,243 @@
+package converter
+
+import (
+	"sort"
+	"strings"
+)
+
+// Currency describes an ISO 4217 currency.
+type Currency struct {
+	Code       string `json:"code"`
+	Numeric    int    `json:"numeric"`
+	MinorUnits int    `json:"minor_units"`
+	Symbol     string `json:"symbol"`
+	Name       string `json:"name"`
+}
+
+// isoCurrencies lists the active ISO 4217 currencies, excluding funds, precious metals and testing codes.
+var isoCurrencies = []Currency{
+	{"AED", 784, 2, "د.إ", "UAE Dirham"},
+	{"AFN", 971, 2, "؋", "Afghani"},
+	{"ALL", 8, 2, "L", "Lek"},
+	{"AMD", 51, 2, "֏", "Armenian Dram"},
+	{"ANG", 532, 2, "ƒ", "Netherlands Antillean Guilder"},
+	{"AOA", 973, 2, "Kz", "Kwanza"},
+	{"ARS", 32, 2, "$", "Argentine Peso"},
+	{"AUD", 36, 2, "A$", "Australian Dollar"},
+	{"AWG", 533, 2, "ƒ", "Aruban Florin"},
+	{"AZN", 944, 2, "₼", "Azerbaijan Manat"},
+	{"BAM", 977, 2, "KM", "Convertible Mark"},
+	{"BBD", 52, 2, "Bds$", "Barbados Dollar"},
+	{"BDT", 50, 2, "৳", "Taka"},
+	{"BGN", 975, 2, "лв", "Bulgarian Lev"},
+	{"BHD", 48, 3, ".د.ب", "Bahraini Dinar"},
+	{"BIF", 108, 0, "FBu", "Burundi Franc"},
+	{"BMD", 60, 2, "$", "Bermudian Dollar"},
+	{"BND", 96, 2, "B$", "Brunei Dollar"},
+	{"BOB", 68, 2, "Bs", "Boliviano"},
+	{"BRL", 986, 2, "R$", "Brazilian Real"},
+	{"BSD", 44, 2, "$", "Bahamian Dollar"},
+	{"BTN", 64, 2, "Nu.", "Ngultrum"},
+	{"BWP", 72, 2, "P", "Pula"},
+	{"BYN", 933, 2, "Br", "Belarusian Ruble"},
+	{"BZD", 84, 2, "BZ$", "Belize Dollar"},
+	{"CAD", 124, 2, "CA$", "Canadian Dollar"},
+	{"CDF", 976, 2, "FC", "Congolese Franc"},
+	{"CHF", 756, 2, "CHF", "Swiss Franc"},
+	{"CLP", 152, 0, "$", "Chilean Peso"},
+	{"CNY", 156, 2, "CN¥", "Yuan Renminbi"},
+	{"COP", 170, 2, "$", "Colombian Peso"},
+	{"CRC", 188, 2, "₡", "Costa Rican Colon"},
+	{"CUP", 192, 2, "$", "Cuban Peso"},
+	{"CVE", 132, 2, "Esc", "Cabo Verde Escudo"},
+	{"CZK", 203, 2, "Kč", "Czech Koruna"},
+	{"DJF", 262, 0, "Fdj", "Djibouti Franc"},
+	{"DKK", 208, 2, "kr", "Danish Krone"},
+	{"DOP", 214, 2, "RD$", "Dominican Peso"},
+	{"DZD", 12, 2, "د.ج", "Algerian Dinar"},
+	{"EGP", 818, 2, "E£", "Egyptian Pound"},
+	{"ERN", 232, 2, "Nfk", "Nakfa"},
+	{"ETB", 230, 2, "Br", "Ethiopian Birr"},
+	{"EUR", 978, 2, "€", "Euro"},
+	{"FJD", 242, 2, "FJ$", "Fiji Dollar"},
+	{"FKP", 238, 2, "£", "Falkland Islands Pound"},
+	{"GBP", 826, 2, "£", "Pound Sterling"},
+	{"GEL", 981, 2, "₾", "Lari"},
+	{"GHS", 936, 2, "GH₵", "Ghana Cedi"},
+	{"GIP", 292, 2, "£", "Gibraltar Pound"},
+	{"GMD", 270, 2, "D", "Dalasi"},
+	{"GNF", 324, 0, "FG", "Guinean Franc"},
+	{"GTQ", 320, 2, "Q", "Quetzal"},
+	{"GYD", 328, 2, "G$", "Guyana Dollar"},
+	{"HKD", 344, 2, "HK$", "Hong Kong Dollar"},
+	{"HNL", 340, 2, "L", "Lempira"},
+	{"HTG", 332, 2, "G", "Gourde"},
+	{"HUF", 348, 2, "Ft", "Forint"},
+	{"IDR", 360, 2, "Rp", "Rupiah"},
+	{"ILS", 376, 2, "₪", "New Israeli Sheqel"},
+	{"INR", 356, 2, "₹", "Indian Rupee"},
+	{"IQD", 368, 3, "ع.د", "Iraqi Dinar"},
+	{"IRR", 364, 2, "﷼", "Iranian Rial"},
+	{"ISK", 352, 0, "kr", "Iceland Krona"},
+	{"JMD", 388, 2, "J$", "Jamaican Dollar"},
+	{"JOD", 400, 3, "JD", "Jordanian Dinar"},
+	{"JPY", 392, 0, "¥", "Yen"},
+	{"KES", 404, 2, "KSh", "Kenyan Shilling"},
+	{"KGS", 417, 2, "с", "Som"},
+	{"KHR", 116, 2, "៛", "Riel"},
+	{"KMF", 174, 0, "CF", "Comorian Franc"},
+	{"KPW", 408, 2, "₩", "North Korean Won"},
+	{"KRW", 410, 0, "₩", "Won"},
+	{"KWD", 414, 3, "KD", "Kuwaiti Dinar"},
+	{"KYD", 136, 2, "CI$", "Cayman Islands Dollar"},
+	{"KZT", 398, 2, "₸", "Tenge"},
+	{"LAK", 418, 2, "₭", "Lao Kip"},
+	{"LBP", 422, 2, "ل.ل", "Lebanese Pound"},
+	{"LKR", 144, 2, "Rs", "Sri Lanka Rupee"},
+	{"LRD", 430, 2, "L$", "Liberian Dollar"},
+	{"LSL", 426, 2, "L", "Loti"},
+	{"LYD", 434, 3, "LD", "Libyan Dinar"},
+	{"MAD", 504, 2, "DH", "Moroccan Dirham"},
+	{"MDL", 498, 2, "L", "Moldovan Leu"},
+	{"MGA", 969, 2, "Ar", "Malagasy Ariary"},
+	{"MKD", 807, 2, "ден", "Denar"},
+	{"MMK", 104, 2, "K", "Kyat"},
+	{"MNT", 496, 2, "₮", "Tugrik"},
+	{"MOP", 446, 2, "MOP$", "Pataca"},
+	{"MRU", 929, 2, "UM", "Ouguiya"},
+	{"MUR", 480, 2, "Rs", "Mauritius Rupee"},
+	{"MVR", 462, 2, "Rf", "Rufiyaa"},
+	{"MWK", 454, 2, "MK", "Malawi Kwacha"},
+	{"MXN", 484, 2, "Mex$", "Mexican Peso"},
+	{"MYR", 458, 2, "RM", "Malaysian Ringgit"},
+	{"MZN", 943, 2, "MT", "Mozambique Metical"},
+	{"NAD", 516, 2, "N$", "Namibia Dollar"},
+	{"NGN", 566, 2, "₦", "Naira"},
+	{"NIO", 558, 2, "C$", "Cordoba Oro"},
+	{"NOK", 578, 2, "kr", "Norwegian Krone"},
+	{"NPR", 524, 2, "Rs", "Nepalese Rupee"},
+	{"NZD", 554, 2, "NZ$", "New Zealand Dollar"},
+	{"OMR", 512, 3, "ر.ع.", "Rial Omani"},
+	{"PAB", 590, 2, "B/.", "Balboa"},
+	{"PEN", 604, 2, "S/", "Sol"},
+	{"PGK", 598, 2, "K", "Kina"},
+	{"PHP", 608, 2, "₱", "Philippine Peso"},
+	{"PKR", 586, 2, "Rs", "Pakistan Rupee"},
+	{"PLN", 985, 2, "zł", "Zloty"},
+	{"PYG", 600, 0, "₲", "Guarani"},
+	{"QAR", 634, 2, "QR", "Qatari Rial"},
+	{"RON", 946, 2, "lei", "Romanian Leu"},
+	{"RSD", 941, 2, "дин", "Serbian Dinar"},
+	{"RUB", 643, 2, "₽", "Russian Ruble"},
+	{"RWF", 646, 0, "FRw", "Rwanda Franc"},
+	{"SAR", 682, 2, "SR", "Saudi Riyal"},
+	{"SBD", 90, 2, "SI$", "Solomon Islands Dollar"},
+	{"SCR", 690, 2, "SRe", "Seychelles Rupee"},
+	{"SDG", 938, 2, "SDG", "Sudanese Pound"},
+	{"SEK", 752, 2, "kr", "Swedish Krona"},
+	{"SGD", 702, 2, "S$", "Singapore Dollar"},
+	{"SHP", 654, 2, "£", "Saint Helena Pound"},
+	{"SLE", 925, 2, "Le", "Leone"},
+	{"SOS", 706, 2, "Sh", "Somali Shilling"},
+	{"SRD", 968, 2, "$", "Surinam Dollar"},
+	{"SSP", 728, 2, "£", "South Sudanese Pound"},
+	{"STN", 930, 2, "Db", "Dobra"},
+	{"SVC", 222, 2, "₡", "El Salvador Colon"},
+	{"SYP", 760, 2, "£S", "Syrian Pound"},
+	{"SZL", 748, 2, "E", "Lilangeni"},
+	{"THB", 764, 2, "฿", "Baht"},
+	{"TJS", 972, 2, "SM", "Somoni"},
+	{"TMT", 934, 2, "m", "Turkmenistan New Manat"},
+	{"TND", 788, 3, "DT", "Tunisian Dinar"},
+	{"TOP", 776, 2, "T$", "Pa'anga"},
+	{"TRY", 949, 2, "₺", "Turkish Lira"},
+	{"TTD", 780, 2, "TT$", "Trinidad and Tobago Dollar"},
+	{"TWD", 901, 2, "NT$", "New Taiwan Dollar"},
+	{"TZS", 834, 2, "TSh", "Tanzanian Shilling"},
+	{"UAH", 980, 2, "₴", "Hryvnia"},
+	{"UGX", 800, 0, "USh", "Uganda Shilling"},
+	{"USD", 840, 2, "$", "US Dollar"},
+	{"UYU", 858, 2, "$U", "Peso Uruguayo"},
+	{"UZS", 860, 2, "soʻm", "Uzbekistan Sum"},
+	{"VED", 926, 2, "Bs.D", "Bolívar Soberano"},
+	{"VES", 928, 2, "Bs.S", "Bolívar Soberano"},
+	{"VND", 704, 0, "₫", "Dong"},
+	{"VUV", 548, 0, "VT", "Vatu"},
+	{"WST", 882, 2, "WS$", "Tala"},
+	{"XAF", 950, 0, "FCFA", "CFA Franc BEAC"},
+	{"XCD", 951, 2, "EC$", "East Caribbean Dollar"},
+	{"XOF", 952, 0, "CFA", "CFA Franc BCEAO"},
+	{"XPF", 953, 0, "₣", "CFP Franc"},
+	{"YER", 886, 2, "﷼", "Yemeni Rial"},
+	{"ZAR", 710, 2, "R", "Rand"},
+	{"ZMW", 967, 2, "ZK", "Zambian Kwacha"},
+	{"ZWG", 924, 2, "ZiG", "Zimbabwe Gold"},
+}
+
+// currencyByCode indexes isoCurrencies by upper-case code.
+var currencyByCode = indexCurrencies(isoCurrencies)
+
+func indexCurrencies(list []Currency) map[string]Currency {
+	index := make(map[string]Currency, len(list))
+	for _, c := range list {
+		index[c.Code] = c
+	}
+	return index
+}
+
+// Currencies returns every registered currency, sorted by code.
+func Currencies() []Currency {
+	return append([]Currency(nil), isoCurrencies...)
+}
+
+// LookupCurrency finds a registered currency by its code, ignoring case.
+func LookupCurrency(code string) (Currency, bool) {
+	c, ok := currencyByCode[strings.ToUpper(strings.TrimSpace(code))]
+	return c, ok
+}
+
+// maxSuggestDistance is the largest edit distance for which SuggestCurrency offers a match.
+const maxSuggestDistance = 2
+
+// SuggestCurrency returns the registered code closest to the mistyped code,
+// or "" when nothing is close enough to be a likely typo.
+func SuggestCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	best, bestDistance := "", maxSuggestDistance+1
+
+	codes := make([]string, 0, len(currencyByCode))
+	for c := range currencyByCode {
+		codes = append(codes, c)
+	}
+	sort.Strings(codes)
+
+	for _, c := range codes {
+		if d := editDistance(code, c); d < bestDistance {
+			best, bestDistance = c, d
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,21 @@
+package converter
+
+import "testing"
+
+func TestCurrencyRegistry(t *testing.T) {
+	seen := map[int]string{}
+	for _, c := range isoCurrencies {
+		if len(c.Code) != 3 {
+			t.Errorf("Currency code %q should have three letters", c.Code)
+		}
+		if other, ok := seen[c.Numeric]; ok {
+			t.Errorf("Numeric code %d is used by both %s and %s", c.Numeric, other, c.Code)
+		}
+		seen[c.Numeric] = c.Code
+	}
+
+	jpy, ok := LookupCurrency("jpy")
+	if !ok || jpy.MinorUnits != 0 || jpy.Numeric != 392 {
+		t.Errorf("Unexpected JPY entry: %+v", jpy)
+	}
+}
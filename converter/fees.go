@@ -0,0 +1,134 @@
+package converter
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// FeeRule is what is charged on conversions out of one source currency.
+// Amounts are in the source currency; nil means no flat fee or no cap.
+type FeeRule struct {
+	Flat *big.Rat
+	Min  *big.Rat // the spread and fee together are raised to at least Min
+	Max  *big.Rat // the spread and fee together are capped at Max
+}
+
+// FeePolicy describes what the bank charges on top of the mid-market rate:
+// a percentage spread per currency pair and a fee rule per source currency.
+// The zero policy charges nothing.
+type FeePolicy struct {
+	Spreads map[string]*big.Rat // percent, keyed by "FROM/TO", with "*" as the default
+	Fees    map[string]FeeRule  // keyed by source currency code
+}
+
+// spread returns the percentage spread for a pair.
+func (p FeePolicy) spread(from, to string) *big.Rat {
+	if pct, ok := p.Spreads[from+"/"+to]; ok {
+		return pct
+	}
+	if pct, ok := p.Spreads["*"]; ok {
+		return pct
+	}
+	return new(big.Rat)
+}
+
+// charges works out the spread and flat fee, in the source currency, for converting amount into to.
+// The caps apply to the two together: below Min the fee makes up the difference, and above Max
+// the fee is reduced first and then the spread.
+func (p FeePolicy) charges(amount Money, to Currency) (spread, fee *big.Rat) {
+	spread = new(big.Rat).Mul(amount.Amount, p.spread(amount.Currency.Code, to.Code))
+	spread.Quo(spread, big.NewRat(100, 1))
+
+	rule := p.Fees[amount.Currency.Code]
+	fee = new(big.Rat)
+	if rule.Flat != nil {
+		fee.Set(rule.Flat)
+	}
+
+	total := new(big.Rat).Add(spread, fee)
+	switch {
+	case rule.Min != nil && total.Cmp(rule.Min) < 0:
+		fee.Sub(rule.Min, spread)
+	case rule.Max != nil && total.Cmp(rule.Max) > 0:
+		if spread.Cmp(rule.Max) > 0 {
+			spread.Set(rule.Max)
+		}
+		fee.Sub(rule.Max, spread)
+	}
+	return spread, fee
+}
+
+type jsonFeeRule struct {
+	Flat json.Number `json:"flat"`
+	Min  json.Number `json:"min"`
+	Max  json.Number `json:"max"`
+}
+
+type jsonFeePolicy struct {
+	Spreads map[string]json.Number `json:"spreads"`
+	Fees    map[string]jsonFeeRule `json:"fees"`
+}
+
+// LoadFeePolicy reads a fee policy from a JSON file such as
+// {"spreads":{"EUR/INR":"1.5","*":"0.5"},"fees":{"EUR":{"flat":"2.50","min":"3","max":"25"}}}.
+// Spreads are percentages; fees and caps are amounts of the source currency.
+func LoadFeePolicy(path string) (FeePolicy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return FeePolicy{}, fmt.Errorf("opening fee policy: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(f)
+	dec.UseNumber()
+
+	var raw jsonFeePolicy
+	if err := dec.Decode(&raw); err != nil {
+		return FeePolicy{}, fmt.Errorf("reading fee policy %s: %w", path, err)
+	}
+
+	policy := FeePolicy{Spreads: map[string]*big.Rat{}, Fees: map[string]FeeRule{}}
+	for pair, pct := range raw.Spreads {
+		value, err := feeAmount(pct)
+		if err != nil || value == nil {
+			return FeePolicy{}, fmt.Errorf("invalid spread %q for %s", pct, pair)
+		}
+		policy.Spreads[strings.ToUpper(pair)] = value
+	}
+
+	for code, rule := range raw.Fees {
+		var parsed FeeRule
+		for _, field := range []struct {
+			name  string
+			value json.Number
+			dest  **big.Rat
+		}{{"flat", rule.Flat, &parsed.Flat}, {"min", rule.Min, &parsed.Min}, {"max", rule.Max, &parsed.Max}} {
+			value, err := feeAmount(field.value)
+			if err != nil {
+				return FeePolicy{}, fmt.Errorf("invalid %s fee %q for %s", field.name, field.value, code)
+			}
+			*field.dest = value
+		}
+		if parsed.Min != nil && parsed.Max != nil && parsed.Min.Cmp(parsed.Max) > 0 {
+			return FeePolicy{}, fmt.Errorf("minimum fee for %s is above its maximum", code)
+		}
+		policy.Fees[strings.ToUpper(code)] = parsed
+	}
+
+	return policy, nil
+}
+
+// feeAmount parses an optional non-negative decimal; an empty value gives nil.
+func feeAmount(n json.Number) (*big.Rat, error) {
+	if n == "" {
+		return nil, nil
+	}
+	value, ok := ParseDecimal(n.String())
+	if !ok || value.Sign() < 0 {
+		return nil, fmt.Errorf("invalid amount %q", n)
+	}
+	return value, nil
+}
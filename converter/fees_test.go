@@ -0,0 +1,109 @@
+package converter
+
+import (
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConvertFeeCaps(t *testing.T) {
+	fees := FeePolicy{
+		Spreads: map[string]*big.Rat{"*": big.NewRat(1, 1)},
+		Fees: map[string]FeeRule{
+			"USD": {Flat: big.NewRat(2, 1), Min: big.NewRat(5, 1), Max: big.NewRat(20, 1)},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		amount     int64
+		wantSpread string
+		wantFee    string
+		wantNet    string
+	}{
+		{"Below_Min_Raises_Fee", 100, "1.00", "4.00", "95.00"},
+		{"At_Min", 300, "3.00", "2.00", "295.00"},
+		{"Between_Caps", 1000, "10.00", "2.00", "988.00"},
+		{"At_Max", 1800, "18.00", "2.00", "1780.00"},
+		{"Above_Max_Lowers_Fee", 1900, "19.00", "1.00", "1880.00"},
+		{"Spread_Alone_Above_Max", 2500, "20.00", "0.00", "2480.00"},
+	}
+
+	usd := currencyByCode["USD"]
+	for _, tt := range tests {
+		req := Request{Amount: Money{Amount: big.NewRat(tt.amount, 1), Currency: usd}, To: usd}
+		result, err := Convert(DefaultRates(), req, RoundHalfEven, fees)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+
+		got := []string{result.Spread.Amount.FloatString(2), result.Fee.Amount.FloatString(2), result.Net.Amount.FloatString(2)}
+		want := []string{tt.wantSpread, tt.wantFee, tt.wantNet}
+		for i := range got {
+			if got[i] != want[i] {
+				t.Errorf("%s: expected spread/fee/net %v, got %v", tt.name, want, got)
+				break
+			}
+		}
+	}
+
+	req := Request{Amount: Money{Amount: big.NewRat(3, 1), Currency: usd}, To: usd}
+	if _, err := Convert(DefaultRates(), req, RoundHalfEven, fees); err == nil {
+		t.Errorf("Expected an error when the minimum fee exceeds the amount")
+	}
+}
+
+func TestConvertFeesInTargetCurrency(t *testing.T) {
+	fees := FeePolicy{
+		Spreads: map[string]*big.Rat{"USD/INR": big.NewRat(3, 2)},
+		Fees:    map[string]FeeRule{"USD": {Flat: big.NewRat(1, 1)}},
+	}
+	req := Request{Amount: Money{Amount: big.NewRat(100, 1), Currency: currencyByCode["USD"]}, To: currencyByCode["INR"]}
+
+	result, err := Convert(DefaultRates(), req, RoundHalfEven, fees)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for name, pair := range map[string][2]string{
+		"mid-market": {result.Amount.String(), "8312.00 INR"},
+		"spread":     {result.Spread.String(), "124.68 INR"},
+		"fee":        {result.Fee.String(), "83.12 INR"},
+		"net":        {result.Net.String(), "8104.20 INR"},
+	} {
+		if pair[0] != pair[1] {
+			t.Errorf("Expected %s %s, got %s", name, pair[1], pair[0])
+		}
+	}
+}
+
+func TestLoadFeePolicy(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "fees.json")
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(good, []byte(`{"spreads":{"eur/inr":"1.5","*":0.5},"fees":{"EUR":{"flat":"2.50","max":25}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte(`{"fees":{"EUR":{"min":"30","max":"25"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := LoadFeePolicy(good)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := policy.spread("EUR", "INR").FloatString(1); got != "1.5" {
+		t.Errorf("Expected EUR/INR spread 1.5, got %s", got)
+	}
+	if got := policy.spread("USD", "JPY").FloatString(1); got != "0.5" {
+		t.Errorf("Expected default spread 0.5, got %s", got)
+	}
+	if rule := policy.Fees["EUR"]; rule.Min != nil || rule.Max.FloatString(0) != "25" {
+		t.Errorf("Unexpected EUR fee rule: %+v", rule)
+	}
+
+	if _, err := LoadFeePolicy(bad); err == nil {
+		t.Errorf("Expected an error for a minimum above the maximum")
+	}
+}
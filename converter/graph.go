@@ -0,0 +1,200 @@
+package converter
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// arbitrageTolerance is the relative gap between a quoted rate and the rate implied
+// by the other quotes above which the table is reported as inconsistent.
+const arbitrageTolerance = 0.001
+
+// PairRate is a direct quote: one unit of From buys Rate units of To.
+type PairRate struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Rate float64 `json:"rate"`
+}
+
+// rateGraph links currencies by the quotes in a rate table. Every quote gives an edge
+// in each direction; direct pair quotes replace base quotes for the same pair.
+type rateGraph struct {
+	edges  map[string]map[string]*big.Rat
+	quotes []PairRate
+}
+
+// newRateGraph builds the graph of base quotes and direct pair quotes in table. Quotes
+// that are not positive finite rates are left out, as no conversion can use them.
+func newRateGraph(table RateTable) rateGraph {
+	g := rateGraph{edges: map[string]map[string]*big.Rat{}}
+
+	codes := make([]string, 0, len(table.Rates))
+	for code := range table.Rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	g.node(table.Base)
+	for _, code := range codes {
+		if rate := table.Rates[code]; code != table.Base && checkRate(rate) == nil {
+			g.add(PairRate{From: table.Base, To: code, Rate: rate})
+		}
+	}
+	for _, pair := range table.Pairs {
+		if pair.From != pair.To && checkRate(pair.Rate) == nil {
+			g.add(pair)
+		}
+	}
+	return g
+}
+
+func (g rateGraph) node(code string) map[string]*big.Rat {
+	if g.edges[code] == nil {
+		g.edges[code] = map[string]*big.Rat{}
+	}
+	return g.edges[code]
+}
+
+func (g *rateGraph) add(q PairRate) {
+	rate := ratFromFloat(q.Rate)
+	g.node(q.From)[q.To] = rate
+	g.node(q.To)[q.From] = new(big.Rat).Inv(rate)
+	g.quotes = append(g.quotes, q)
+}
+
+// neighbours returns the currencies directly quoted against code, sorted for stable routes.
+func (g rateGraph) neighbours(code string) []string {
+	next := make([]string, 0, len(g.edges[code]))
+	for to := range g.edges[code] {
+		next = append(next, to)
+	}
+	sort.Strings(next)
+	return next
+}
+
+// route finds how to convert from one currency to another: a direct quote when there
+// is one, otherwise the path with the fewest hops. It returns the currencies on the
+// path, including both ends, and the combined rate.
+func (g rateGraph) route(from, to string) ([]string, *big.Rat, error) {
+	if _, ok := g.edges[from]; !ok {
+		return nil, nil, fmt.Errorf("no exchange rate for source currency %s", from)
+	}
+	if _, ok := g.edges[to]; !ok {
+		return nil, nil, fmt.Errorf("no exchange rate for destination currency %s", to)
+	}
+	if from == to {
+		return []string{from}, big.NewRat(1, 1), nil
+	}
+
+	parent := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 && parent[to] == "" {
+		code := queue[0]
+		queue = queue[1:]
+		for _, next := range g.neighbours(code) {
+			if _, seen := parent[next]; !seen {
+				parent[next] = code
+				queue = append(queue, next)
+			}
+		}
+	}
+	if _, ok := parent[to]; !ok {
+		return nil, nil, fmt.Errorf("no conversion path from %s to %s", from, to)
+	}
+
+	path := []string{to}
+	for code := to; code != from; {
+		code = parent[code]
+		path = append([]string{code}, path...)
+	}
+
+	rate := big.NewRat(1, 1)
+	for i := 1; i < len(path); i++ {
+		rate.Mul(rate, g.edges[path[i-1]][path[i]])
+	}
+	return path, rate, nil
+}
+
+// arbitrageWarnings checks every quote against the rate implied by the rest of the graph.
+// A consistent table has the same rate along every path between two currencies, so any
+// quote that disagrees with the spanning tree closes a cycle whose rates do not multiply to 1.
+func (g rateGraph) arbitrageWarnings() []string {
+	// value[c] is how many units of c one unit of its tree's root buys.
+	value := map[string]*big.Rat{}
+	parent := map[string]string{}
+	roots := make([]string, 0, len(g.edges))
+	for code := range g.edges {
+		roots = append(roots, code)
+	}
+	sort.Strings(roots)
+
+	for _, root := range roots {
+		if _, done := value[root]; done {
+			continue
+		}
+		value[root] = big.NewRat(1, 1)
+		queue := []string{root}
+		for len(queue) > 0 {
+			code := queue[0]
+			queue = queue[1:]
+			for _, next := range g.neighbours(code) {
+				if _, done := value[next]; !done {
+					value[next] = new(big.Rat).Mul(value[code], g.edges[code][next])
+					parent[next] = code
+					queue = append(queue, next)
+				}
+			}
+		}
+	}
+
+	tolerance := new(big.Rat).SetFloat64(arbitrageTolerance)
+	var warnings []string
+	for _, q := range g.quotes {
+		implied := new(big.Rat).Quo(value[q.To], value[q.From])
+		gap := new(big.Rat).Quo(ratFromFloat(q.Rate), implied)
+		gap.Sub(gap, big.NewRat(1, 1))
+		if gap.Abs(gap).Cmp(tolerance) <= 0 {
+			continue
+		}
+
+		gapPct, _ := gap.Float64()
+		warnings = append(warnings, fmt.Sprintf("inconsistent rates: %s→%s is quoted at %s but %s implies %s (%.2f%% apart)",
+			q.From, q.To, ratFromFloat(q.Rate).FloatString(RateDecimals),
+			FormatPath(treePath(parent, q.From, q.To)), implied.FloatString(RateDecimals), gapPct*100))
+	}
+	return warnings
+}
+
+// treePath returns the path between two currencies in the spanning tree described by parent.
+func treePath(parent map[string]string, from, to string) []string {
+	ancestors := func(code string) []string {
+		chain := []string{code}
+		for p, ok := parent[code]; ok; p, ok = parent[p] {
+			chain = append(chain, p)
+		}
+		return chain
+	}
+
+	up, down := ancestors(from), ancestors(to)
+	onUp := map[string]int{}
+	for i, code := range up {
+		onUp[code] = i
+	}
+	for j, code := range down {
+		if i, ok := onUp[code]; ok {
+			path := append([]string{}, up[:i+1]...)
+			for k := j - 1; k >= 0; k-- {
+				path = append(path, down[k])
+			}
+			return path
+		}
+	}
+	return []string{from, to}
+}
+
+// FormatPath renders a conversion path such as EUR→USD→JPY.
+func FormatPath(path []string) string {
+	return strings.Join(path, "→")
+}
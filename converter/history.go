@@ -0,0 +1,171 @@
+package converter
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxLookbackDays bounds how far before the requested date RateHistory searches for a quote.
+const maxLookbackDays = 10
+
+// RateHistory is a time series of rate tables, one per quote date, sorted oldest first.
+type RateHistory struct {
+	Tables []RateTable
+}
+
+// newRateHistory sorts the tables by date into a RateHistory.
+func newRateHistory(tables []RateTable) RateHistory {
+	sort.SliceStable(tables, func(i, j int) bool { return tables[i].Date.Before(tables[j].Date) })
+	return RateHistory{Tables: tables}
+}
+
+// Rates returns the table quoted on the given date. When that date has no quote,
+// the nearest earlier business day with a quote is used instead; the returned
+// table's Date says which day that was. A zero date returns the latest table.
+func (h RateHistory) Rates(on time.Time) (RateTable, error) {
+	if len(h.Tables) == 0 {
+		return RateTable{}, fmt.Errorf("rate history is empty")
+	}
+	if on.IsZero() {
+		return h.Tables[len(h.Tables)-1], nil
+	}
+
+	on = time.Date(on.Year(), on.Month(), on.Day(), 0, 0, 0, 0, time.UTC)
+	earliest := on.AddDate(0, 0, -maxLookbackDays)
+
+	i := sort.Search(len(h.Tables), func(i int) bool { return h.Tables[i].Date.After(on) }) - 1
+	for ; i >= 0 && !h.Tables[i].Date.Before(earliest); i-- {
+		table := h.Tables[i]
+		if table.Date.Equal(on) || isBusinessDay(table.Date) {
+			return table, nil
+		}
+	}
+
+	return RateTable{}, &NoRatesError{On: on, Reason: fmt.Sprintf("no rates on %s or the %d days before it", on.Format(time.DateOnly), maxLookbackDays)}
+}
+
+// isBusinessDay reports whether day falls on a weekday.
+func isBusinessDay(day time.Time) bool {
+	return day.Weekday() != time.Saturday && day.Weekday() != time.Sunday
+}
+
+// LoadRateHistory reads a time series of rates from a .json, .csv or ECB historical .xml file.
+// JSON files look like {"base":"USD","rates":{"2024-06-12":{"EUR":0.92}}}.
+// CSV files hold "date,currency,rate" rows; each date's row with rate 1 is taken as its base.
+func LoadRateHistory(path string) (RateHistory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return RateHistory{}, fmt.Errorf("opening rate history: %w", err)
+	}
+	defer f.Close()
+
+	var tables []RateTable
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		tables, err = decodeJSONHistory(f)
+	case ".csv":
+		tables, err = decodeCSVHistory(f)
+	case ".xml":
+		tables, err = decodeECBRates(f)
+	default:
+		return RateHistory{}, fmt.Errorf("unsupported rate history format %q, use .json, .csv or .xml", filepath.Ext(path))
+	}
+	if err != nil {
+		return RateHistory{}, fmt.Errorf("reading rate history %s: %w", path, err)
+	}
+	return newRateHistory(tables), nil
+}
+
+type jsonHistory struct {
+	Base  string                        `json:"base"`
+	Rates map[string]map[string]float64 `json:"rates"`
+}
+
+func decodeJSONHistory(r io.Reader) ([]RateTable, error) {
+	var raw jsonHistory
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("decoding JSON history: %w", err)
+	}
+	if raw.Base == "" {
+		return nil, fmt.Errorf("JSON history has no base currency")
+	}
+
+	base := strings.ToUpper(raw.Base)
+	tables := make([]RateTable, 0, len(raw.Rates))
+	for day, rates := range raw.Rates {
+		date, err := time.Parse(time.DateOnly, day)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rate date %q: %w", day, err)
+		}
+
+		table := RateTable{Base: base, Date: date, Rates: map[string]float64{base: 1}}
+		for code, rate := range rates {
+			if err := checkRate(rate); err != nil {
+				return nil, fmt.Errorf("rate for %s on %s: %w", code, day, err)
+			}
+			table.Rates[strings.ToUpper(code)] = rate
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+func decodeCSVHistory(r io.Reader) ([]RateTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	byDate := map[string]*RateTable{}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decoding CSV history: %w", err)
+		}
+
+		date, err := time.Parse(time.DateOnly, record[0])
+		if err != nil {
+			if line == 1 {
+				continue // header row
+			}
+			return nil, fmt.Errorf("line %d: invalid date %q", line, record[0])
+		}
+		rate, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid rate %q", line, record[2])
+		}
+		if err := checkRate(rate); err != nil {
+			return nil, fmt.Errorf("line %d: rate for %s: %w", line, record[1], err)
+		}
+
+		table, ok := byDate[record[0]]
+		if !ok {
+			table = &RateTable{Date: date, Rates: map[string]float64{}}
+			byDate[record[0]] = table
+		}
+		code := strings.ToUpper(record[1])
+		table.Rates[code] = rate
+		if rate == 1 && table.Base == "" {
+			table.Base = code
+		}
+	}
+
+	tables := make([]RateTable, 0, len(byDate))
+	for day, table := range byDate {
+		if table.Base == "" {
+			return nil, fmt.Errorf("rates for %s have no base currency row with rate 1", day)
+		}
+		tables = append(tables, *table)
+	}
+	return tables, nil
+}
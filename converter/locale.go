@@ -0,0 +1,246 @@
+package converter
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Locale describes how amounts are written in a region.
+type Locale struct {
+	Tag         string
+	Decimal     string
+	Group       string
+	Grouping    []int // digit group sizes from the right; the last size repeats
+	SymbolFirst bool
+	SymbolSpace bool
+}
+
+// locales lists the locales accepted by --locale, keyed by lower-case tag.
+var locales = map[string]Locale{
+	"en-us": {Tag: "en-US", Decimal: ".", Group: ",", Grouping: []int{3}, SymbolFirst: true},
+	"en-gb": {Tag: "en-GB", Decimal: ".", Group: ",", Grouping: []int{3}, SymbolFirst: true},
+	"en-in": {Tag: "en-IN", Decimal: ".", Group: ",", Grouping: []int{3, 2}, SymbolFirst: true},
+	"de-de": {Tag: "de-DE", Decimal: ",", Group: ".", Grouping: []int{3}, SymbolSpace: true},
+	"de-ch": {Tag: "de-CH", Decimal: ".", Group: "’", Grouping: []int{3}, SymbolFirst: true, SymbolSpace: true},
+	"fr-fr": {Tag: "fr-FR", Decimal: ",", Group: "\u202f", Grouping: []int{3}, SymbolSpace: true},
+	"es-es": {Tag: "es-ES", Decimal: ",", Group: ".", Grouping: []int{3}, SymbolSpace: true},
+	"it-it": {Tag: "it-IT", Decimal: ",", Group: ".", Grouping: []int{3}, SymbolSpace: true},
+	"nl-nl": {Tag: "nl-NL", Decimal: ",", Group: ".", Grouping: []int{3}, SymbolFirst: true, SymbolSpace: true},
+	"pt-br": {Tag: "pt-BR", Decimal: ",", Group: ".", Grouping: []int{3}, SymbolFirst: true, SymbolSpace: true},
+	"ja-jp": {Tag: "ja-JP", Decimal: ".", Group: ",", Grouping: []int{3}, SymbolFirst: true},
+	"zh-cn": {Tag: "zh-CN", Decimal: ".", Group: ",", Grouping: []int{3}, SymbolFirst: true},
+}
+
+// currencyLocales picks the locale used to print a currency when no --locale is given.
+var currencyLocales = map[string]string{
+	"INR": "en-in",
+	"EUR": "de-de",
+	"CHF": "de-ch",
+	"GBP": "en-gb",
+	"JPY": "ja-jp",
+	"CNY": "zh-cn",
+	"BRL": "pt-br",
+}
+
+// LookupLocale finds a locale by its tag, ignoring case and accepting "_" for "-".
+func LookupLocale(tag string) (Locale, bool) {
+	l, ok := locales[strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))]
+	return l, ok
+}
+
+// LocaleForCurrency returns the locale an amount of c is printed in by default.
+func LocaleForCurrency(c Currency) Locale {
+	if tag, ok := currencyLocales[c.Code]; ok {
+		return locales[tag]
+	}
+	return locales["en-us"]
+}
+
+// FormatMoney writes m with its currency symbol, separators and grouping. A nil
+// locale uses the currency's own locale, so rupees get lakh and crore grouping.
+func FormatMoney(m Money, loc *Locale) string {
+	l := LocaleForCurrency(m.Currency)
+	if loc != nil {
+		l = *loc
+	}
+	return l.Format(m)
+}
+
+// Format writes m following the locale's conventions.
+func (l Locale) Format(m Money) string {
+	digits := m.Amount.FloatString(m.Currency.MinorUnits)
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+
+	whole, frac, _ := strings.Cut(digits, ".")
+	number := l.group(whole)
+	if frac != "" {
+		number += l.Decimal + frac
+	}
+
+	symbol := m.Currency.Symbol
+	space := ""
+	if l.SymbolSpace {
+		space = "\u00a0"
+	}
+
+	var out string
+	if l.SymbolFirst {
+		out = symbol + space + number
+	} else {
+		out = number + space + symbol
+	}
+	if negative {
+		out = "-" + out
+	}
+	return out
+}
+
+// group inserts group separators into a string of digits.
+func (l Locale) group(digits string) string {
+	if len(l.Grouping) == 0 {
+		return digits
+	}
+
+	var groups []string
+	for i, end := 0, len(digits); end > 0; i++ {
+		size := l.Grouping[min(i, len(l.Grouping)-1)]
+		start := max(end-size, 0)
+		groups = append([]string{digits[start:end]}, groups...)
+		end = start
+	}
+	return strings.Join(groups, l.Group)
+}
+
+// ParseAmount reads an amount such as "1,234.50", "1.234,50", "₹1,00,000" or "CHF 1’234.50".
+// A leading or trailing currency symbol or code is ignored. With a locale, only its
+// separators are accepted; without one, the separators are worked out from the text.
+func ParseAmount(s string, loc *Locale) (*big.Rat, bool) {
+	s = stripCurrencyAffixes(strings.TrimSpace(s))
+	if s == "" {
+		return nil, false
+	}
+
+	decimal, groups := "", []string(nil)
+	if loc != nil {
+		decimal, groups = loc.Decimal, []string{loc.Group}
+		if isSpace(loc.Group) {
+			groups = append(groups, " ", "\u00a0", "\u202f")
+		}
+		if loc.Group == "’" {
+			groups = append(groups, "'")
+		}
+	} else {
+		decimal, groups = detectSeparators(s)
+	}
+
+	// The decimal separator may appear once and no group separator may follow it.
+	if strings.Count(s, decimal) > 1 {
+		return nil, false
+	}
+	if i := strings.Index(s, decimal); i >= 0 {
+		for _, g := range groups {
+			if strings.Contains(s[i:], g) {
+				return nil, false
+			}
+		}
+	}
+
+	for _, g := range groups {
+		s = strings.ReplaceAll(s, g, "\x00")
+	}
+	if !validGroups(s, decimal) {
+		return nil, false
+	}
+	s = strings.ReplaceAll(s, "\x00", "")
+	if decimal != "." {
+		s = strings.Replace(s, decimal, ".", 1)
+	}
+	return ParseDecimal(s)
+}
+
+// validGroups checks the digit groups before the decimal separator, which are
+// marked by NUL bytes in s: the first group holds one to three digits and the
+// others two (lakh and crore grouping) or three.
+func validGroups(s, decimal string) bool {
+	whole, _, _ := strings.Cut(s, decimal)
+	parts := strings.Split(whole, "\x00")
+	if len(parts) == 1 {
+		return true
+	}
+	if n := len(strings.TrimLeft(parts[0], "+-")); n < 1 || n > 3 {
+		return false
+	}
+	for _, part := range parts[1:] {
+		if len(part) != 2 && len(part) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// detectSeparators guesses the decimal and group separators of an amount written in an unknown locale.
+func detectSeparators(s string) (string, []string) {
+	groups := []string{" ", "\u00a0", "\u202f", "'", "’"}
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+
+	switch {
+	case dot >= 0 && comma >= 0:
+		// Whichever comes last is the decimal separator: 1,234.50 or 1.234,50.
+		if comma > dot {
+			return ",", append(groups, ".")
+		}
+		return ".", append(groups, ",")
+	case comma >= 0:
+		// A single comma followed by anything but three digits is a decimal comma: 1,5 or 12,50.
+		if strings.Count(s, ",") == 1 && len(s)-comma-1 != 3 {
+			return ",", groups
+		}
+		return ".", append(groups, ",")
+	case dot >= 0 && strings.Count(s, ".") > 1:
+		return ",", append(groups, ".")
+	default:
+		return ".", groups
+	}
+}
+
+// currencyAffixes holds every registered symbol and code, longest first, for stripping from amounts.
+var currencyAffixes = buildCurrencyAffixes()
+
+func buildCurrencyAffixes() []string {
+	seen := map[string]bool{}
+	var affixes []string
+	for _, c := range isoCurrencies {
+		for _, a := range []string{c.Symbol, c.Code} {
+			if a != "" && !seen[a] {
+				seen[a] = true
+				affixes = append(affixes, a)
+			}
+		}
+	}
+	sort.SliceStable(affixes, func(i, j int) bool { return len(affixes[i]) > len(affixes[j]) })
+	return affixes
+}
+
+// stripCurrencyAffixes removes one currency symbol or code from each end of s.
+func stripCurrencyAffixes(s string) string {
+	for _, a := range currencyAffixes {
+		if strings.HasPrefix(s, a) {
+			s = strings.TrimSpace(strings.TrimPrefix(s, a))
+			break
+		}
+	}
+	for _, a := range currencyAffixes {
+		if strings.HasSuffix(s, a) {
+			s = strings.TrimSpace(strings.TrimSuffix(s, a))
+			break
+		}
+	}
+	return s
+}
+
+func isSpace(s string) bool {
+	return strings.TrimFunc(s, unicode.IsSpace) == ""
+}
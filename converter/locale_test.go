@@ -0,0 +1,81 @@
+package converter
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		in     string
+		locale string
+		want   string
+		ok     bool
+	}{
+		{"1234.50", "", "1234.50", true},
+		{"1,234.50", "", "1234.50", true},
+		{"1.234,50", "", "1234.50", true},
+		{"₹1,00,000", "", "100000.00", true},
+		{"1,5", "", "1.50", true},
+		{"1.234.567", "", "1234567.00", true},
+		{"CHF 1’234.50", "", "1234.50", true},
+		{"1.5e3", "", "1500.00", true},
+		{"1 234,50 €", "fr-FR", "1234.50", true},
+		{"1.234,50", "de-DE", "1234.50", true},
+		{"1,234.50", "de-DE", "", false},
+		{"1,00,000.25", "en-IN", "100000.25", true},
+		{"12..5", "", "", false},
+		{"abc", "", "", false},
+		{"$", "", "", false},
+	}
+
+	for _, tt := range tests {
+		var loc *Locale
+		if tt.locale != "" {
+			l, _ := LookupLocale(tt.locale)
+			loc = &l
+		}
+
+		got, ok := ParseAmount(tt.in, loc)
+		if ok != tt.ok {
+			t.Errorf("ParseAmount(%q, %s): expected ok=%v, got %v", tt.in, tt.locale, tt.ok, ok)
+			continue
+		}
+		if ok && got.FloatString(2) != tt.want {
+			t.Errorf("ParseAmount(%q, %s): expected %s, got %s", tt.in, tt.locale, tt.want, got.FloatString(2))
+		}
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	amount := func(s, code string) Money {
+		r, _ := new(big.Rat).SetString(s)
+		return Money{Amount: r, Currency: currencyByCode[code]}
+	}
+
+	tests := []struct {
+		money  Money
+		locale string
+		want   string
+	}{
+		{amount("1234567.891", "USD"), "", "$1,234,567.89"},
+		{amount("12345678.5", "INR"), "", "₹1,23,45,678.50"},
+		{amount("1234.5", "EUR"), "", "1.234,50\u00a0€"},
+		{amount("1234.5", "EUR"), "fr-FR", "1\u202f234,50\u00a0€"},
+		{amount("15745", "JPY"), "", "¥15,745"},
+		{amount("-999.5", "GBP"), "", "-£999.50"},
+		{amount("1234.5", "CHF"), "", "CHF\u00a01’234.50"},
+		{amount("0.5", "KWD"), "", "KD0.500"},
+	}
+
+	for _, tt := range tests {
+		var loc *Locale
+		if tt.locale != "" {
+			l, _ := LookupLocale(tt.locale)
+			loc = &l
+		}
+		if got := FormatMoney(tt.money, loc); got != tt.want {
+			t.Errorf("FormatMoney(%s, %s): expected %q, got %q", tt.money, tt.locale, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package converter
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// RoundingMode selects how amounts are rounded to a currency's minor units.
+type RoundingMode int
+
+const (
+	// RoundHalfEven rounds ties to the nearest even minor unit (banker's rounding).
+	RoundHalfEven RoundingMode = iota
+	// RoundHalfUp rounds ties away from zero.
+	RoundHalfUp
+	// RoundTruncate drops everything below the minor unit.
+	RoundTruncate
+)
+
+// String returns the name used for the mode on the command line.
+func (m RoundingMode) String() string {
+	switch m {
+	case RoundHalfEven:
+		return "half-even"
+	case RoundHalfUp:
+		return "half-up"
+	case RoundTruncate:
+		return "truncate"
+	default:
+		return fmt.Sprintf("RoundingMode(%d)", int(m))
+	}
+}
+
+// ParseRoundingMode reads a rounding mode name as accepted by --round.
+func ParseRoundingMode(name string) (RoundingMode, error) {
+	switch strings.ToLower(name) {
+	case "", "half-even":
+		return RoundHalfEven, nil
+	case "half-up":
+		return RoundHalfUp, nil
+	case "truncate":
+		return RoundTruncate, nil
+	default:
+		return 0, fmt.Errorf("unknown rounding mode %q, use half-even, half-up or truncate", name)
+	}
+}
+
+// Money is an exact decimal amount of a currency.
+type Money struct {
+	Amount   *big.Rat
+	Currency Currency
+}
+
+// ParseDecimal reads a plain decimal number such as "1234.50" or "1.5e3" without going through float64.
+func ParseDecimal(s string) (*big.Rat, bool) {
+	if strings.ContainsAny(s, "/xXbBoO_") {
+		return nil, false
+	}
+	return new(big.Rat).SetString(s)
+}
+
+// ratFromFloat converts a rate to a rational using its shortest decimal form,
+// so 0.92 becomes exactly 92/100 rather than the nearest binary fraction.
+func ratFromFloat(f float64) *big.Rat {
+	r, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return r
+}
+
+// Round returns m rounded to the minor units of its currency.
+func (m Money) Round(mode RoundingMode) Money {
+	return Money{Amount: roundRat(m.Amount, m.Currency.MinorUnits, mode), Currency: m.Currency}
+}
+
+// String formats m with exactly as many decimals as its currency has minor units.
+func (m Money) String() string {
+	return m.Amount.FloatString(m.Currency.MinorUnits) + " " + m.Currency.Code
+}
+
+// roundRat rounds x to the given number of decimal places.
+func roundRat(x *big.Rat, places int, mode RoundingMode) *big.Rat {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	scaled := new(big.Rat).Mul(x, new(big.Rat).SetInt(scale))
+
+	q, r := new(big.Int).QuoRem(scaled.Num(), scaled.Denom(), new(big.Int))
+
+	// Compare twice the remainder with the denominator to find which side of the half we are on.
+	twice := new(big.Int).Abs(r)
+	twice.Lsh(twice, 1)
+	half := twice.Cmp(scaled.Denom())
+
+	roundAway := false
+	switch mode {
+	case RoundHalfUp:
+		roundAway = half >= 0
+	case RoundHalfEven:
+		roundAway = half > 0 || (half == 0 && q.Bit(0) == 1)
+	case RoundTruncate:
+	}
+	if roundAway && r.Sign() != 0 {
+		q.Add(q, big.NewInt(int64(r.Sign())))
+	}
+
+	return new(big.Rat).SetFrac(q, scale)
+}
@@ -0,0 +1,111 @@
+package converter
+
+import (
+	"math/big"
+	"testing"
+	"testing/quick"
+)
+
+func TestRoundRat(t *testing.T) {
+	tests := []struct {
+		in     string
+		places int
+		mode   RoundingMode
+		want   string
+	}{
+		{"2.345", 2, RoundHalfEven, "2.34"},
+		{"2.355", 2, RoundHalfEven, "2.36"},
+		{"2.345", 2, RoundHalfUp, "2.35"},
+		{"-2.345", 2, RoundHalfUp, "-2.35"},
+		{"-2.345", 2, RoundHalfEven, "-2.34"},
+		{"2.349", 2, RoundTruncate, "2.34"},
+		{"-2.349", 2, RoundTruncate, "-2.34"},
+		{"157.5", 0, RoundHalfEven, "158"},
+		{"156.5", 0, RoundHalfEven, "156"},
+		{"1.0005", 3, RoundHalfUp, "1.001"},
+	}
+
+	for _, tt := range tests {
+		x, _ := new(big.Rat).SetString(tt.in)
+		got := roundRat(x, tt.places, tt.mode).FloatString(tt.places)
+		if got != tt.want {
+			t.Errorf("round(%s, %d, %s): expected %s, got %s", tt.in, tt.places, tt.mode, tt.want, got)
+		}
+	}
+}
+
+// roundTripPairs are converted there and back by the property tests.
+var roundTripPairs = [][2]string{
+	{"INR", "JPY"}, {"JPY", "INR"}, {"USD", "EUR"}, {"EUR", "JPY"}, {"USD", "INR"}, {"JPY", "USD"},
+}
+
+func TestExactRoundTripIsIdentity(t *testing.T) {
+	rates := DefaultRates().Table
+
+	property := func(minor int64, pair uint8) bool {
+		p := roundTripPairs[int(pair)%len(roundTripPairs)]
+		from, to := currencyByCode[p[0]], currencyByCode[p[1]]
+		amount := Money{Amount: big.NewRat(minor, 100), Currency: from}
+
+		there, err := ConvertExact(rates, amount, to)
+		if err != nil {
+			return false
+		}
+		back, err := ConvertExact(rates, there, from)
+		return err == nil && back.Amount.Cmp(amount.Amount) == 0
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRoundedRoundTripIsBounded(t *testing.T) {
+	rates := DefaultRates().Table
+
+	property := func(units uint32, pair uint8, halfUp bool) bool {
+		p := roundTripPairs[int(pair)%len(roundTripPairs)]
+		from, to := currencyByCode[p[0]], currencyByCode[p[1]]
+		mode := RoundHalfEven
+		if halfUp {
+			mode = RoundHalfUp
+		}
+
+		start := Money{Amount: big.NewRat(int64(units), 1), Currency: from}.Round(mode)
+		there, err := ConvertExact(rates, start, to)
+		if err != nil {
+			return false
+		}
+		back, err := ConvertExact(rates, there.Round(mode), from)
+		if err != nil {
+			return false
+		}
+		back = back.Round(mode)
+
+		// Rounding in the target loses at most half a target minor unit, worth
+		// halfTo in the source currency, and rounding back adds half a source unit.
+		halfTo, _ := ConvertExact(rates, Money{Amount: minorUnit(to, 2), Currency: to}, from)
+		bound := new(big.Rat).Add(halfTo.Amount, minorUnit(from, 2))
+
+		drift := new(big.Rat).Sub(back.Amount, start.Amount)
+		if drift.Abs(drift).Cmp(bound) > 0 {
+			return false
+		}
+
+		// When half a target unit is worth less than half a source unit the round trip is exact.
+		if halfTo.Amount.Cmp(minorUnit(from, 2)) < 0 {
+			return back.Amount.Cmp(start.Amount) == 0
+		}
+		return true
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// minorUnit returns one minor unit of c divided by div.
+func minorUnit(c Currency, div int64) *big.Rat {
+	unit := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(c.MinorUnits)), nil))
+	return unit.Quo(unit, big.NewRat(div, 1))
+}
@@ -0,0 +1,363 @@
+package converter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateTable holds how many units of each currency one unit of Base buys,
+// plus any direct quotes between other pairs of currencies.
+type RateTable struct {
+	Base  string
+	Date  time.Time
+	Rates map[string]float64
+	Pairs []PairRate
+	Stale bool // served from an expired cache entry
+}
+
+// RateProvider supplies the exchange rates used by Convert.
+// Rates returns the table in effect on the given date; a zero date asks for the latest rates.
+type RateProvider interface {
+	Rates(on time.Time) (RateTable, error)
+}
+
+// NoRatesError reports that a provider has no rates for the requested date. It is
+// the caller's date that cannot be served, not the rate source that failed.
+type NoRatesError struct {
+	On     time.Time
+	Reason string
+}
+
+func (e *NoRatesError) Error() string { return e.Reason }
+
+// StaticProvider serves a fixed rate table, either built in or loaded from a file.
+type StaticProvider struct {
+	Table RateTable
+}
+
+// Rates returns the provider's fixed table. Dated lookups only succeed when the table
+// itself is dated, following the same nearest-earlier-business-day rule as RateHistory.
+func (p StaticProvider) Rates(on time.Time) (RateTable, error) {
+	if on.IsZero() {
+		return p.Table, nil
+	}
+	if p.Table.Date.IsZero() {
+		return RateTable{}, &NoRatesError{On: on, Reason: "rate table is undated, use --history for rates on " + on.Format(time.DateOnly)}
+	}
+	return RateHistory{Tables: []RateTable{p.Table}}.Rates(on)
+}
+
+// Built-in rates against USD, used when no --rates, --rates-url or --history source is given.
+const (
+	usdRate = 1.0
+	eurRate = 0.92
+	inrRate = 83.12
+	jpyRate = 157.45
+)
+
+// RateDecimals is the number of decimals used when a rate is printed.
+const RateDecimals = 6
+
+// DefaultRates returns the built-in USD based rates used when no other source is configured.
+func DefaultRates() StaticProvider {
+	return StaticProvider{Table: RateTable{
+		Base: "USD",
+		Rates: map[string]float64{
+			"USD": usdRate,
+			"EUR": eurRate,
+			"INR": inrRate,
+			"JPY": jpyRate,
+		},
+	}}
+}
+
+// LoadRateFile reads a rate table from a .json or .csv file.
+// JSON files look like {"base":"USD","date":"2024-06-12","rates":{"EUR":0.92},"pairs":[{"from":"EUR","to":"INR","rate":90.3}]}.
+// CSV files hold "currency,rate" rows, where the row with rate 1 is taken as the base,
+// and "from,to,rate" rows for direct pair quotes.
+func LoadRateFile(path string) (StaticProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return StaticProvider{}, fmt.Errorf("opening rate file: %w", err)
+	}
+	defer f.Close()
+
+	var table RateTable
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		table, err = decodeJSONRates(f)
+	case ".csv":
+		table, err = decodeCSVRates(f)
+	default:
+		return StaticProvider{}, fmt.Errorf("unsupported rate file format %q, use .json or .csv", filepath.Ext(path))
+	}
+	if err != nil {
+		return StaticProvider{}, fmt.Errorf("reading rate file %s: %w", path, err)
+	}
+	return StaticProvider{Table: table}, nil
+}
+
+// HTTPProvider fetches rates from an ECB-style XML feed or a JSON feed in the rate file layout.
+type HTTPProvider struct {
+	URL    string
+	Client *http.Client
+}
+
+// Rates downloads and decodes the feed. The format is picked from the first byte of the body.
+// ECB historical feeds carry several days, from which the one in effect on the given date is picked.
+func (p HTTPProvider) Rates(on time.Time) (RateTable, error) {
+	client := p.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, p.URL, http.NoBody)
+	if err != nil {
+		return RateTable{}, fmt.Errorf("building rate request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return RateTable{}, fmt.Errorf("fetching rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return RateTable{}, fmt.Errorf("fetching rates: unexpected status %s", resp.Status)
+	}
+
+	body := bufio.NewReader(resp.Body)
+	first, err := peekNonSpace(body)
+	if err != nil {
+		return RateTable{}, fmt.Errorf("reading rate feed: %w", err)
+	}
+	if first != '<' {
+		table, err := decodeJSONRates(body)
+		if err != nil {
+			return RateTable{}, err
+		}
+		return StaticProvider{Table: table}.Rates(on)
+	}
+
+	days, err := decodeECBRates(body)
+	if err != nil {
+		return RateTable{}, err
+	}
+	return newRateHistory(days).Rates(on)
+}
+
+// NewRateProvider picks the rate source from the command line options, falling back to the built-in table.
+// Remote feeds are cached for cacheTTL; a zero cacheTTL fetches the feed on every lookup.
+func NewRateProvider(file, url, history string, cacheTTL time.Duration) (RateProvider, error) {
+	sources := 0
+	for _, source := range []string{file, url, history} {
+		if source != "" {
+			sources++
+		}
+	}
+
+	switch {
+	case sources > 1:
+		return nil, fmt.Errorf("use only one of --rates, --rates-url and --history")
+	case file != "":
+		return LoadRateFile(file)
+	case history != "":
+		return LoadRateHistory(history)
+	case url != "" && cacheTTL > 0:
+		return NewCachingProvider(HTTPProvider{URL: url}, cacheTTL), nil
+	case url != "":
+		return HTTPProvider{URL: url}, nil
+	default:
+		return DefaultRates(), nil
+	}
+}
+
+// RateSourceName describes the rate source chosen by the same options as NewRateProvider, for audit records.
+func RateSourceName(file, url, history string) string {
+	switch {
+	case file != "":
+		return "file " + file
+	case history != "":
+		return "history " + history
+	case url != "":
+		return "url " + url
+	default:
+		return "built-in"
+	}
+}
+
+// MarshalJSON writes the table in the same layout LoadRateFile reads.
+func (t RateTable) MarshalJSON() ([]byte, error) {
+	raw := jsonRates{Base: t.Base, Rates: t.Rates, Pairs: t.Pairs, Stale: t.Stale}
+	if !t.Date.IsZero() {
+		raw.Date = t.Date.Format(time.DateOnly)
+	}
+	return json.Marshal(raw)
+}
+
+type jsonRates struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date,omitempty"`
+	Rates map[string]float64 `json:"rates"`
+	Pairs []PairRate         `json:"pairs,omitempty"`
+	Stale bool               `json:"stale,omitempty"`
+}
+
+func decodeJSONRates(r io.Reader) (RateTable, error) {
+	var raw jsonRates
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return RateTable{}, fmt.Errorf("decoding JSON rates: %w", err)
+	}
+	if raw.Base == "" {
+		return RateTable{}, fmt.Errorf("JSON rates have no base currency")
+	}
+
+	table := RateTable{Base: strings.ToUpper(raw.Base), Rates: make(map[string]float64, len(raw.Rates))}
+	for code, rate := range raw.Rates {
+		if err := checkRate(rate); err != nil {
+			return RateTable{}, fmt.Errorf("rate for %s: %w", code, err)
+		}
+		table.Rates[strings.ToUpper(code)] = rate
+	}
+	for _, pair := range raw.Pairs {
+		if err := checkRate(pair.Rate); err != nil {
+			return RateTable{}, fmt.Errorf("rate for %s/%s: %w", pair.From, pair.To, err)
+		}
+		table.Pairs = append(table.Pairs, PairRate{From: strings.ToUpper(pair.From), To: strings.ToUpper(pair.To), Rate: pair.Rate})
+	}
+	if raw.Date != "" {
+		date, err := time.Parse(time.DateOnly, raw.Date)
+		if err != nil {
+			return RateTable{}, fmt.Errorf("invalid rate date %q: %w", raw.Date, err)
+		}
+		table.Date = date
+	}
+	table.Rates[table.Base] = 1
+	return table, nil
+}
+
+func decodeCSVRates(r io.Reader) (RateTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	table := RateTable{Rates: map[string]float64{}}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return RateTable{}, fmt.Errorf("decoding CSV rates: %w", err)
+		}
+		if len(record) != 2 && len(record) != 3 {
+			return RateTable{}, fmt.Errorf("line %d: expected currency,rate or from,to,rate", line)
+		}
+
+		rateField := record[len(record)-1]
+		rate, err := strconv.ParseFloat(rateField, 64)
+		if err != nil {
+			if line == 1 {
+				continue // header row
+			}
+			return RateTable{}, fmt.Errorf("line %d: invalid rate %q", line, rateField)
+		}
+		if err := checkRate(rate); err != nil {
+			return RateTable{}, fmt.Errorf("line %d: rate for %s: %w", line, strings.Join(record[:len(record)-1], "/"), err)
+		}
+
+		if len(record) == 3 {
+			table.Pairs = append(table.Pairs, PairRate{From: strings.ToUpper(record[0]), To: strings.ToUpper(record[1]), Rate: rate})
+			continue
+		}
+		code := strings.ToUpper(record[0])
+		table.Rates[code] = rate
+		if rate == 1 && table.Base == "" {
+			table.Base = code
+		}
+	}
+
+	if table.Base == "" {
+		return RateTable{}, fmt.Errorf("CSV rates have no base currency row with rate 1")
+	}
+	return table, nil
+}
+
+// checkRate rejects rates no conversion can use: zero, negative, infinite or not a number.
+// Every rate decoder checks each rate it reads with it.
+func checkRate(rate float64) error {
+	if math.IsNaN(rate) || math.IsInf(rate, 0) || rate <= 0 {
+		return fmt.Errorf("%v is not a positive finite rate", rate)
+	}
+	return nil
+}
+
+// ecbEnvelope mirrors the layout of the ECB eurofxref feeds, whose rates are quoted against EUR.
+// The daily feed holds a single day, the historical feeds one Cube per day.
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string  `xml:"currency,attr"`
+			Rate     float64 `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+func decodeECBRates(r io.Reader) ([]RateTable, error) {
+	var env ecbEnvelope
+	if err := xml.NewDecoder(r).Decode(&env); err != nil {
+		return nil, fmt.Errorf("decoding ECB rates: %w", err)
+	}
+	if len(env.Days) == 0 {
+		return nil, fmt.Errorf("ECB feed contains no rates")
+	}
+
+	days := make([]RateTable, 0, len(env.Days))
+	for _, day := range env.Days {
+		table := RateTable{Base: "EUR", Rates: map[string]float64{"EUR": 1}}
+		if day.Time != "" {
+			date, err := time.Parse(time.DateOnly, day.Time)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ECB date %q: %w", day.Time, err)
+			}
+			table.Date = date
+		}
+		for _, rate := range day.Rates {
+			if err := checkRate(rate.Rate); err != nil {
+				return nil, fmt.Errorf("ECB rate for %s on %s: %w", rate.Currency, day.Time, err)
+			}
+			table.Rates[rate.Currency] = rate.Rate
+		}
+		days = append(days, table)
+	}
+	return days, nil
+}
+
+// peekNonSpace returns the first non-whitespace byte of r without consuming it.
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsAny(b, " \t\r\n") {
+			return b[0], nil
+		}
+		if _, err := r.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+}
@@ -0,0 +1,278 @@
+package converter
+
+import (
+	"math"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const ecbFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2024-06-12">
+			<Cube currency="USD" rate="1.0812"/>
+			<Cube currency="JPY" rate="169.85"/>
+			<Cube currency="INR" rate="90.30"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestHTTPProvider(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantBase string
+		wantUSD  float64
+	}{
+		{
+			name:     "ECB_XML",
+			body:     ecbFeed,
+			wantBase: "EUR",
+			wantUSD:  1.0812,
+		},
+		{
+			name:     "JSON",
+			body:     `{"base":"EUR","date":"2024-06-12","rates":{"USD":1.08,"JPY":169.85}}`,
+			wantBase: "EUR",
+			wantUSD:  1.08,
+		},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte(tt.body))
+		}))
+
+		table, err := HTTPProvider{URL: server.URL, Client: server.Client()}.Rates(time.Time{})
+		server.Close()
+
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if table.Base != tt.wantBase {
+			t.Errorf("%s: expected base %s, got %s", tt.name, tt.wantBase, table.Base)
+		}
+		if got := table.Rates["USD"]; got != tt.wantUSD {
+			t.Errorf("%s: expected USD rate %v, got %v", tt.name, tt.wantUSD, got)
+		}
+		if got := table.Date.Format(time.DateOnly); got != "2024-06-12" {
+			t.Errorf("%s: expected rate date 2024-06-12, got %s", tt.name, got)
+		}
+	}
+}
+
+func TestHTTPProviderBadStatus(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	if _, err := (HTTPProvider{URL: server.URL, Client: server.Client()}).Rates(time.Time{}); err == nil {
+		t.Errorf("Expected an error for a 404 feed")
+	}
+}
+
+func TestLoadRateFile(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"rates.json": `{"base":"USD","rates":{"EUR":0.9,"INR":83}}`,
+		"rates.csv":  "currency,rate\nUSD,1\nEUR,0.9\nINR,83\n",
+	}
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		provider, err := LoadRateFile(path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+
+		amount := Money{Amount: big.NewRat(90, 1), Currency: currencyByCode["EUR"]}
+		got, err := Convert(provider, Request{Amount: amount, To: currencyByCode["INR"]}, RoundHalfEven, FeePolicy{})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if got.Amount.String() != "8300.00 INR" {
+			t.Errorf("%s: expected 8300.00 INR, got %s", name, got.Amount)
+		}
+	}
+}
+
+func TestLoadRateFileRejectsBadRates(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"inf.csv":       "USD,1\nEUR,Inf\n",
+		"nan.csv":       "USD,1\nEUR,NaN\n",
+		"zero.csv":      "USD,1\nEUR,0\n",
+		"negative.json": `{"base":"USD","rates":{"EUR":-0.9}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := LoadRateFile(path)
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		} else if strings.HasSuffix(name, ".csv") && !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("%s: expected the error to name line 2, got %v", name, err)
+		}
+	}
+
+	if _, err := decodeECBRates(strings.NewReader(strings.Replace(ecbFeed, "1.0812", "Inf", 1))); err == nil {
+		t.Errorf("Expected an error for an infinite ECB rate")
+	}
+}
+
+func TestConvertCurrencyUnknownRate(t *testing.T) {
+	amount := Money{Amount: big.NewRat(1, 1), Currency: currencyByCode["USD"]}
+	req := Request{Amount: amount, To: currencyByCode["GBP"]}
+	if _, err := Convert(DefaultRates(), req, RoundHalfEven, FeePolicy{}); err == nil {
+		t.Errorf("Expected an error for a currency missing from the rate table")
+	}
+}
+
+func TestLoadRateHistoryRejectsBadRates(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"inf.csv":   "2024-06-12,USD,1\n2024-06-12,EUR,+Inf\n",
+		"zero.json": `{"base":"USD","rates":{"2024-06-12":{"EUR":0}}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadRateHistory(path); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestRateHistoryUsesNearestEarlierBusinessDay(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse(time.DateOnly, s)
+		return d
+	}
+	history := newRateHistory([]RateTable{
+		{Base: "USD", Date: day("2024-06-14"), Rates: map[string]float64{"EUR": 0.93}}, // Friday
+		{Base: "USD", Date: day("2024-06-13"), Rates: map[string]float64{"EUR": 0.92}}, // Thursday
+		{Base: "USD", Date: day("2024-06-15"), Rates: map[string]float64{"EUR": 0.99}}, // stray Saturday quote
+	})
+
+	tests := []struct {
+		on      string
+		want    string
+		wantErr bool
+	}{
+		{on: "2024-06-13", want: "2024-06-13"},
+		{on: "2024-06-15", want: "2024-06-15"},
+		{on: "2024-06-16", want: "2024-06-14"},
+		{on: "2024-06-17", want: "2024-06-14"},
+		{on: "2024-07-30", wantErr: true},
+		{on: "2024-06-01", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		table, err := history.Rates(day(tt.on))
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got rates of %s", tt.on, table.Date.Format(time.DateOnly))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.on, err)
+			continue
+		}
+		if got := table.Date.Format(time.DateOnly); got != tt.want {
+			t.Errorf("%s: expected rates of %s, got %s", tt.on, tt.want, got)
+		}
+	}
+}
+
+func TestRateGraphRoute(t *testing.T) {
+	table := RateTable{
+		Base:  "USD",
+		Rates: map[string]float64{"EUR": 0.92, "JPY": 157.45},
+		Pairs: []PairRate{{From: "EUR", To: "INR", Rate: 90.35}, {From: "INR", To: "LKR", Rate: 3.6}},
+	}
+	graph := newRateGraph(table)
+
+	tests := []struct {
+		from, to string
+		wantPath string
+		wantRate string
+	}{
+		{"EUR", "INR", "EUR→INR", "90.350000"},
+		{"INR", "EUR", "INR→EUR", "0.011068"},
+		{"EUR", "JPY", "EUR→USD→JPY", "171.141304"},
+		{"USD", "LKR", "USD→EUR→INR→LKR", "299.239200"},
+		{"JPY", "JPY", "JPY", "1.000000"},
+	}
+
+	for _, tt := range tests {
+		path, rate, err := graph.route(tt.from, tt.to)
+		if err != nil {
+			t.Errorf("%s→%s: unexpected error: %v", tt.from, tt.to, err)
+			continue
+		}
+		if got := FormatPath(path); got != tt.wantPath {
+			t.Errorf("%s→%s: expected path %s, got %s", tt.from, tt.to, tt.wantPath, got)
+		}
+		if got := rate.FloatString(6); got != tt.wantRate {
+			t.Errorf("%s→%s: expected rate %s, got %s", tt.from, tt.to, tt.wantRate, got)
+		}
+	}
+
+	if _, _, err := graph.route("USD", "GBP"); err == nil {
+		t.Errorf("Expected an error for a currency without quotes")
+	}
+}
+
+func TestRateGraphSkipsUnusableRates(t *testing.T) {
+	table := RateTable{
+		Base:  "USD",
+		Rates: map[string]float64{"EUR": math.Inf(1), "INR": math.NaN(), "JPY": 157.45},
+		Pairs: []PairRate{{From: "JPY", To: "EUR", Rate: math.Inf(1)}},
+	}
+	graph := newRateGraph(table)
+	for _, to := range []string{"EUR", "INR"} {
+		if _, _, err := graph.route("USD", to); err == nil {
+			t.Errorf("USD→%s: expected an error for an unusable rate", to)
+		}
+	}
+	if _, _, err := graph.route("USD", "JPY"); err != nil {
+		t.Errorf("USD→JPY: unexpected error: %v", err)
+	}
+}
+
+func TestRateGraphArbitrageWarnings(t *testing.T) {
+	consistent := RateTable{
+		Base:  "USD",
+		Rates: map[string]float64{"EUR": 0.92, "INR": 83.12},
+		Pairs: []PairRate{{From: "EUR", To: "INR", Rate: 90.3478}},
+	}
+	if warnings := newRateGraph(consistent).arbitrageWarnings(); len(warnings) != 0 {
+		t.Errorf("Expected no warnings for consistent rates, got %v", warnings)
+	}
+
+	inconsistent := consistent
+	inconsistent.Pairs = []PairRate{{From: "EUR", To: "INR", Rate: 95}}
+	warnings := newRateGraph(inconsistent).arbitrageWarnings()
+	if len(warnings) != 1 {
+		t.Fatalf("Expected one warning, got %v", warnings)
+	}
+	want := "inconsistent rates: USD→INR is quoted at 83.120000 but USD→EUR→INR implies 87.400000 (4.90% apart)"
+	if warnings[0] != want {
+		t.Errorf("Expected warning %q, got %q", want, warnings[0])
+	}
+}
@@ -0,0 +1,72 @@
+package converter
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// maxReverseDoublings bounds the search for an amount large enough to cover the target,
+// so a policy whose spread eats the whole amount fails instead of looping.
+const maxReverseDoublings = 64
+
+// fixedProvider serves one table for every date, so a search converts against a single snapshot.
+type fixedProvider struct {
+	table RateTable
+}
+
+func (p fixedProvider) Rates(time.Time) (RateTable, error) {
+	return p.table, nil
+}
+
+// ConvertReverse works out how much of from has to be sent for target to be received after
+// the fee policy. The answer is the smallest amount, in whole minor units of from, whose
+// Net is at least target; the returned Request and Result are that conversion.
+func ConvertReverse(rates RateProvider, from Currency, target Money, date time.Time, mode RoundingMode, fees FeePolicy) (Request, Result, error) {
+	table, err := rates.Rates(date)
+	if err != nil {
+		return Request{}, Result{}, fmt.Errorf("%w: %w", ErrRateSource, err)
+	}
+	estimate, err := ConvertExact(table, target, from)
+	if err != nil {
+		return Request{}, Result{}, err
+	}
+
+	snapshot := fixedProvider{table: table}
+	unit := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(from.MinorUnits)), nil))
+	convert := func(units *big.Int) (Request, Result, bool) {
+		amount := new(big.Rat).Mul(new(big.Rat).SetInt(units), unit)
+		req := Request{Amount: Money{Amount: amount, Currency: from}, To: target.Currency, Date: date}
+		result, err := Convert(snapshot, req, mode, fees)
+		return req, result, err == nil && result.Net.Amount.Cmp(target.Amount) >= 0
+	}
+
+	// Start from the mid-market estimate and double until the fees are covered.
+	units := new(big.Rat).Quo(estimate.Amount, unit)
+	hi := new(big.Int).Quo(units.Num(), units.Denom())
+	hi.Add(hi, big.NewInt(1))
+	for i := 0; ; i++ {
+		if _, _, ok := convert(hi); ok {
+			break
+		}
+		if i == maxReverseDoublings {
+			return Request{}, Result{}, fmt.Errorf("no amount of %s receives %s after fees", from.Code, target)
+		}
+		hi.Lsh(hi, 1)
+	}
+
+	// Bisect on minor units: lo never reaches the target, hi always does.
+	lo := new(big.Int)
+	for one := big.NewInt(1); new(big.Int).Sub(hi, lo).Cmp(one) > 0; {
+		mid := new(big.Int).Add(lo, hi)
+		mid.Rsh(mid, 1)
+		if _, _, ok := convert(mid); ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	req, result, _ := convert(hi)
+	return req, result, nil
+}
@@ -0,0 +1,52 @@
+package converter
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestConvertReverse(t *testing.T) {
+	fees := FeePolicy{
+		Spreads: map[string]*big.Rat{"*": big.NewRat(1, 2)},
+		Fees:    map[string]FeeRule{"EUR": {Flat: big.NewRat(5, 2), Min: big.NewRat(3, 1)}},
+	}
+
+	tests := []struct {
+		name     string
+		from     string
+		target   Money
+		fees     FeePolicy
+		wantSend string
+	}{
+		{"No_Fees", "USD", Money{Amount: big.NewRat(92, 1), Currency: currencyByCode["EUR"]}, FeePolicy{}, "100.00"},
+		{"Spread", "USD", Money{Amount: big.NewRat(10000, 1), Currency: currencyByCode["JPY"]}, fees, "63.83"},
+		{"Minimum_Fee", "EUR", Money{Amount: big.NewRat(1000, 1), Currency: currencyByCode["INR"]}, fees, "14.07"},
+	}
+
+	for _, tt := range tests {
+		req, result, err := ConvertReverse(DefaultRates(), currencyByCode[tt.from], tt.target, time.Time{}, RoundHalfEven, tt.fees)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if got := req.Amount.Amount.FloatString(2); got != tt.wantSend {
+			t.Errorf("%s: expected to send %s, got %s", tt.name, tt.wantSend, got)
+		}
+		if result.Net.Amount.Cmp(tt.target.Amount) < 0 {
+			t.Errorf("%s: net %s is below the target %s", tt.name, result.Net, tt.target)
+		}
+
+		// One minor unit less must fall short, or the answer was not the smallest.
+		less := Money{Amount: new(big.Rat).Sub(req.Amount.Amount, big.NewRat(1, 100)), Currency: req.Amount.Currency}
+		if smaller, err := Convert(DefaultRates(), Request{Amount: less, To: tt.target.Currency}, RoundHalfEven, tt.fees); err == nil && smaller.Net.Amount.Cmp(tt.target.Amount) >= 0 {
+			t.Errorf("%s: sending %s already nets %s", tt.name, less, smaller.Net)
+		}
+	}
+
+	everything := FeePolicy{Spreads: map[string]*big.Rat{"*": big.NewRat(100, 1)}}
+	target := Money{Amount: big.NewRat(1, 1), Currency: currencyByCode["EUR"]}
+	if _, _, err := ConvertReverse(DefaultRates(), currencyByCode["USD"], target, time.Time{}, RoundHalfEven, everything); err == nil {
+		t.Errorf("Expected an error when the spread takes the whole amount")
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"assignment/converter"
+)
+
+// replHistoryName is the file in the home directory that keeps interactive commands between sessions.
+const replHistoryName = ".converter_history"
+
+// maxReplHistory is how many commands are loaded back from the history file.
+const maxReplHistory = 500
+
+const replHelp = `Commands:
+  250 EUR in INR               convert an amount
+  how much USD for 10000 JPY   amount of USD to send for 10000 JPY to arrive after fees
+  ... on 2024-06-12            use the rates in effect on a date
+  history                      list previous commands
+  !N, !!                       run command N, or the last command, again
+  help, quit`
+
+// replSession is the interactive converter started when no arguments are given.
+// The rate provider is loaded once and shared by every command.
+type replSession struct {
+	rates    converter.RateProvider
+	mode     converter.RoundingMode
+	fees     converter.FeePolicy
+	showFees bool
+	audit    *converter.AuditLog
+
+	history     []string
+	historyFile string // commands are appended here; empty keeps the history in memory only
+}
+
+// run reads commands from in until it is exhausted or the user quits.
+func (s *replSession) run(in io.Reader, out io.Writer) {
+	s.loadHistory()
+	fmt.Fprintln(out, `Type a conversion such as "250 EUR in INR", "help" or "quit".`)
+
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+			continue
+		case "quit", "exit":
+			return
+		case "help":
+			fmt.Fprintln(out, replHelp)
+			continue
+		case "history":
+			for i, cmd := range s.history {
+				fmt.Fprintf(out, "%4d  %s\n", i+1, cmd)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			recalled, err := s.recall(line)
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			line = recalled
+			fmt.Fprintln(out, line)
+		}
+
+		s.remember(line)
+		if err := s.execute(out, line); err != nil {
+			fmt.Fprintln(out, err)
+		}
+	}
+}
+
+// execute runs one conversion command.
+func (s *replSession) execute(out io.Writer, line string) error {
+	fields := strings.Fields(line)
+
+	date := ""
+	if n := len(fields); n > 2 && strings.EqualFold(fields[n-2], "on") {
+		date, fields = fields[n-1], fields[:n-2]
+	}
+
+	switch {
+	case len(fields) == 6 && strings.EqualFold(fields[0], "how") && strings.EqualFold(fields[1], "much") && strings.EqualFold(fields[3], "for"):
+		return s.reverse(out, fields[2], fields[4], fields[5], date)
+	case len(fields) == 4 && (strings.EqualFold(fields[2], "in") || strings.EqualFold(fields[2], "to")):
+		req, err := converter.ValidateRequest(fields[0], fields[1], fields[3], date, "")
+		if err != nil {
+			return err
+		}
+		result, err := converter.Convert(s.rates, req, s.mode, s.fees)
+		if err != nil {
+			return err
+		}
+		if err := s.audit.Record(req, result, s.mode); err != nil {
+			return err
+		}
+		printResult(out, req, result, s.mode, s.showFees)
+		return nil
+	default:
+		return fmt.Errorf("unrecognised command %q, type help for examples", line)
+	}
+}
+
+// reverse answers "how much FROM for AMOUNT TO": the amount of FROM to send so that AMOUNT arrives after fees.
+func (s *replSession) reverse(out io.Writer, fromCode, amount, toCode, date string) error {
+	// Validated with the roles as written, so errors name the right currency; the amount
+	// read belongs to the target currency.
+	wanted, err := converter.ValidateRequest(amount, fromCode, toCode, date, "")
+	if err != nil {
+		return err
+	}
+	target := converter.Money{Amount: wanted.Amount.Amount, Currency: wanted.To}
+
+	req, result, err := converter.ConvertReverse(s.rates, wanted.Amount.Currency, target.Round(s.mode), wanted.Date, s.mode, s.fees)
+	if err != nil {
+		return err
+	}
+	if err := s.audit.Record(req, result, s.mode); err != nil {
+		return err
+	}
+
+	printWarnings(out, result)
+	fmt.Fprintf(out, "%s is needed to receive %s \n",
+		converter.FormatMoney(req.Amount, req.Locale), converter.FormatMoney(result.Net, req.Locale))
+	printDetails(out, req, result, s.showFees)
+	return nil
+}
+
+// recall looks up "!!" or "!N" in the history.
+func (s *replSession) recall(ref string) (string, error) {
+	if len(s.history) == 0 {
+		return "", errors.New("history is empty")
+	}
+	if ref == "!!" {
+		return s.history[len(s.history)-1], nil
+	}
+
+	n, err := strconv.Atoi(strings.TrimPrefix(ref, "!"))
+	if err != nil || n < 1 || n > len(s.history) {
+		return "", fmt.Errorf("no command %s in history", ref)
+	}
+	return s.history[n-1], nil
+}
+
+// remember adds a command to the history. Failing to save it is not worth interrupting the session for.
+func (s *replSession) remember(line string) {
+	s.history = append(s.history, line)
+	if s.historyFile == "" {
+		return
+	}
+
+	f, err := os.OpenFile(s.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// loadHistory reads the most recent commands of earlier sessions, if there are any.
+func (s *replSession) loadHistory() {
+	if s.historyFile == "" {
+		return
+	}
+
+	data, err := os.ReadFile(s.historyFile)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			s.history = append(s.history, line)
+		}
+	}
+	if len(s.history) > maxReplHistory {
+		s.history = s.history[len(s.history)-maxReplHistory:]
+	}
+}
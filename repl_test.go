@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"assignment/converter"
+)
+
+func TestREPL(t *testing.T) {
+	historyFile := filepath.Join(t.TempDir(), "history")
+	if err := os.WriteFile(historyFile, []byte("100 USD in EUR\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	session := &replSession{rates: converter.DefaultRates(), mode: converter.RoundHalfEven, historyFile: historyFile}
+	input := strings.Join([]string{
+		"250 usd to EUR",
+		"how much USD for 92 EUR",
+		"!1",
+		"!9",
+		"100 USD into EUR",
+		"how much XYZ for 100 JPY",
+		"history",
+		"quit",
+		"10 USD in EUR",
+	}, "\n")
+
+	var out strings.Builder
+	session.run(strings.NewReader(input), &out)
+
+	for _, want := range []string{
+		"$250.00 is equivalent to 230,00\u00a0€ \n",
+		"$100.00 is needed to receive 92,00\u00a0€ \n",
+		"> 100 USD in EUR\n$100.00 is equivalent to 92,00\u00a0€ \n",
+		"no command !9 in history",
+		`unrecognised command "100 USD into EUR"`,
+		"   4  100 USD in EUR\n   5  100 USD into EUR\n",
+		"unsupported source currency XYZ",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, out.String())
+		}
+	}
+	if strings.Contains(out.String(), "$10.00") {
+		t.Errorf("Expected no commands to run after quit")
+	}
+
+	saved, err := os.ReadFile(historyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(string(saved), "\n"); got != 6 {
+		t.Errorf("Expected 6 commands in the history file, got %d:\n%s", got, saved)
+	}
+}
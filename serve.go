@@ -0,0 +1,156 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"assignment/converter"
+)
+
+// convertResponse is the JSON body returned by GET /convert.
+type convertResponse struct {
+	Amount    string   `json:"amount"`
+	From      string   `json:"from"`
+	To        string   `json:"to"`
+	Converted string   `json:"converted"`
+	Spread    string   `json:"spread"`
+	Fee       string   `json:"fee"`
+	Net       string   `json:"net"`
+	Formatted string   `json:"formatted"`
+	Rate      string   `json:"rate"`
+	RateDate  string   `json:"rate_date,omitempty"`
+	Path      []string `json:"path"`
+	Warnings  []string `json:"warnings,omitempty"`
+	Stale     bool     `json:"stale,omitempty"`
+}
+
+// errorResponse is the JSON body returned for failed requests.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("writing response: %v", err)
+	}
+}
+
+func httpConvert(w http.ResponseWriter, r *http.Request, rates converter.RateProvider, mode converter.RoundingMode, fees converter.FeePolicy, audit *converter.AuditLog) {
+	query := r.URL.Query()
+
+	req, err := converter.ValidateRequest(query.Get("amount"), query.Get("from"), query.Get("to"), query.Get("date"), query.Get("locale"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	result, err := converter.Convert(rates, req, mode, fees)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, converter.ErrRateSource) {
+			status, err = rateErrorStatus(err)
+		}
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+	if err := audit.Record(req, result, mode); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+
+	resp := convertResponse{
+		Amount:    req.Amount.Round(mode).Amount.FloatString(req.Amount.Currency.MinorUnits),
+		From:      req.Amount.Currency.Code,
+		To:        req.To.Code,
+		Converted: result.Amount.Amount.FloatString(result.Amount.Currency.MinorUnits),
+		Spread:    result.Spread.Amount.FloatString(result.Spread.Currency.MinorUnits),
+		Fee:       result.Fee.Amount.FloatString(result.Fee.Currency.MinorUnits),
+		Net:       result.Net.Amount.FloatString(result.Net.Currency.MinorUnits),
+		Formatted: converter.FormatMoney(result.Net, req.Locale),
+		Rate:      result.Rate.FloatString(converter.RateDecimals),
+		Path:      result.Path,
+		Warnings:  result.Warnings,
+		Stale:     result.Stale,
+	}
+	if !result.RateDate.IsZero() {
+		resp.RateDate = result.RateDate.Format(time.DateOnly)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func httpCurrencies(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, converter.Currencies())
+}
+
+func httpRates(w http.ResponseWriter, r *http.Request, rates converter.RateProvider) {
+	var on time.Time
+
+	if date := r.URL.Query().Get("date"); date != "" {
+		var err error
+
+		on, err = time.Parse(time.DateOnly, date)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid date " + date + ", please use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	table, err := rates.Rates(on)
+	if err != nil {
+		status, err := rateErrorStatus(err)
+		writeJSON(w, status, errorResponse{Error: err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, table)
+}
+
+// rateErrorStatus maps a failure to get rates to a response. A date the rates do not cover is
+// the client's problem and gets 422 with a message free of command line hints; anything else
+// means the rate source failed, which is a 502.
+func rateErrorStatus(err error) (int, error) {
+	var noRates *converter.NoRatesError
+	if errors.As(err, &noRates) {
+		return http.StatusUnprocessableEntity, fmt.Errorf("no exchange rates available for %s", noRates.On.Format(time.DateOnly))
+	}
+	return http.StatusBadGateway, err
+}
+
+// newServerMux routes the conversion endpoints to their handlers.
+func newServerMux(rates converter.RateProvider, mode converter.RoundingMode, fees converter.FeePolicy, audit *converter.AuditLog) *http.ServeMux {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /convert", func(w http.ResponseWriter, r *http.Request) { httpConvert(w, r, rates, mode, fees, audit) })
+	mux.HandleFunc("GET /currencies", httpCurrencies)
+	mux.HandleFunc("GET /rates", func(w http.ResponseWriter, r *http.Request) { httpRates(w, r, rates) })
+
+	return mux
+}
+
+// serve runs the conversion HTTP server until it fails.
+func serve(addr string, rates converter.RateProvider, mode converter.RoundingMode, fees converter.FeePolicy, audit *converter.AuditLog) error {
+	server := &http.Server{
+		Addr:    addr,
+		Handler: newServerMux(rates, mode, fees, audit),
+
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	log.Printf("Server starting on port %s", server.Addr)
+	err := server.ListenAndServe()
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"assignment/converter"
+)
+
+func TestHTTPConvert(t *testing.T) {
+	mux := newServerMux(converter.DefaultRates(), converter.RoundHalfEven, converter.FeePolicy{}, nil)
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Converts",
+			url:        "/convert?amount=100&from=usd&to=EUR",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"amount":"100.00","from":"USD","to":"EUR","converted":"92.00","spread":"0.00","fee":"0.00","net":"92.00","formatted":"92,00` + "\u00a0" + `€","rate":"0.920000","path":["USD","EUR"]}`,
+		},
+		{
+			name:       "Locale",
+			url:        "/convert?amount=" + url.QueryEscape("₹1,00,000") + "&from=INR&to=USD&locale=en-IN",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"amount":"100000.00","from":"INR","to":"USD","converted":"1203.08","spread":"0.00","fee":"0.00","net":"1203.08","formatted":"$1,203.08","rate":"0.012031","path":["INR","USD"]}`,
+		},
+		{
+			name:       "Invalid_Amount",
+			url:        "/convert?amount=abc&from=USD&to=EUR",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"invalid amount, please enter the valid amount"}`,
+		},
+		{
+			name:       "Suggests_Currency",
+			url:        "/convert?amount=1&from=USD&to=EUO",
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":"unsupported target currency EUO, did you mean EUR?"}`,
+		},
+		{
+			name:       "No_Rate",
+			url:        "/convert?amount=1&from=USD&to=GBP",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"error":"no exchange rate for destination currency GBP"}`,
+		},
+		{
+			name:       "Undated_Rates",
+			url:        "/convert?amount=1&from=USD&to=EUR&date=2024-06-12",
+			wantStatus: http.StatusUnprocessableEntity,
+			wantBody:   `{"error":"no exchange rates available for 2024-06-12"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, http.NoBody))
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, rec.Code)
+		}
+		if got := rec.Body.String(); got != tt.wantBody+"\n" {
+			t.Errorf("%s: expected body %s, got %s", tt.name, tt.wantBody, got)
+		}
+	}
+}
+
+func TestHTTPCurrenciesAndRates(t *testing.T) {
+	mux := newServerMux(converter.DefaultRates(), converter.RoundHalfEven, converter.FeePolicy{}, nil)
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/currencies", http.NoBody))
+
+	var currencies []converter.Currency
+	if err := json.Unmarshal(rec.Body.Bytes(), &currencies); err != nil {
+		t.Fatalf("decoding currencies: %v", err)
+	}
+	if len(currencies) != len(converter.Currencies()) {
+		t.Errorf("Expected %d currencies, got %d", len(converter.Currencies()), len(currencies))
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/rates", http.NoBody))
+
+	want := `{"base":"USD","rates":{"EUR":0.92,"INR":83.12,"JPY":157.45,"USD":1}}` + "\n"
+	if rec.Body.String() != want {
+		t.Errorf("Expected rates %s, got %s", want, rec.Body.String())
+	}
+}
+
+// failingProvider stands in for a rate feed that cannot be reached.
+type failingProvider struct{}
+
+func (failingProvider) Rates(time.Time) (converter.RateTable, error) {
+	return converter.RateTable{}, errors.New("fetching rates: connection refused")
+}
+
+func TestHTTPRateErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		rates      converter.RateProvider
+		url        string
+		wantStatus int
+		wantBody   string
+	}{
+		{"Feed_Down_Convert", failingProvider{}, "/convert?amount=1&from=USD&to=EUR", http.StatusBadGateway, `{"error":"loading exchange rates: fetching rates: connection refused"}`},
+		{"Feed_Down_Rates", failingProvider{}, "/rates", http.StatusBadGateway, `{"error":"fetching rates: connection refused"}`},
+		{"Undated_Rates", converter.DefaultRates(), "/rates?date=2024-06-12", http.StatusUnprocessableEntity, `{"error":"no exchange rates available for 2024-06-12"}`},
+		{"Before_History", converter.RateHistory{Tables: []converter.RateTable{{Base: "USD", Date: time.Date(2024, 6, 12, 0, 0, 0, 0, time.UTC), Rates: map[string]float64{"EUR": 0.92}}}},
+			"/convert?amount=1&from=USD&to=EUR&date=2024-01-02", http.StatusUnprocessableEntity, `{"error":"no exchange rates available for 2024-01-02"}`},
+	}
+
+	for _, tt := range tests {
+		mux := newServerMux(tt.rates, converter.RoundHalfEven, converter.FeePolicy{}, nil)
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.url, http.NoBody))
+
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: expected status %d, got %d", tt.name, tt.wantStatus, rec.Code)
+		}
+		if got := rec.Body.String(); got != tt.wantBody+"\n" {
+			t.Errorf("%s: expected body %s, got %s", tt.name, tt.wantBody, got)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package tasks
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONStore keeps tasks in a JSON file such as {"last_id":3,"tasks":[{"id":1,...}]}.
+// Every change rewrites the whole file: the new contents are written to a temporary file
+// next to it, which then replaces it, so a crash leaves either the old file or the new one,
+// never a partly written one.
+type JSONStore struct {
+	path string
+
+	mu     sync.Mutex
+	tasks  map[int]Task
+	lastID int
+}
+
+// jsonFile is the layout of the file.
+type jsonFile struct {
+	LastID int    `json:"last_id"`
+	Tasks  []Task `json:"tasks"`
+}
+
+// OpenJSONStore opens the JSON file at path, which need not exist yet.
+func OpenJSONStore(path string) (*JSONStore, error) {
+	s := &JSONStore{path: path, tasks: map[int]Task{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading task store: %w", err)
+	}
+
+	var stored jsonFile
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("reading task store %s: %w", path, err)
+	}
+	for _, task := range stored.Tasks {
+		s.tasks[task.ID] = task
+	}
+	s.lastID = stored.LastID
+	return s, nil
+}
+
+// Load returns every stored task, ordered by ID.
+func (s *JSONStore) Load() ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedTasks(s.tasks), nil
+}
+
+// Save stores the task, replacing the one with the same ID if there is one, and rewrites the file.
+func (s *JSONStore) Save(task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, existed := s.tasks[task.ID]
+	oldLastID := s.lastID
+	s.tasks[task.ID] = task
+	s.lastID = max(s.lastID, task.ID)
+	if err := s.write(); err != nil {
+		if existed {
+			s.tasks[task.ID] = old
+		} else {
+			delete(s.tasks, task.ID)
+		}
+		s.lastID = oldLastID
+		return err
+	}
+	return nil
+}
+
+// Delete removes the task with the ID and rewrites the file.
+func (s *JSONStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, existed := s.tasks[id]
+	if !existed {
+		return nil
+	}
+	delete(s.tasks, id)
+	if err := s.write(); err != nil {
+		s.tasks[id] = old
+		return err
+	}
+	return nil
+}
+
+// LastID returns the highest ID ever saved.
+func (s *JSONStore) LastID() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastID, nil
+}
+
+// Close does nothing; the file is only open while it is being written.
+func (s *JSONStore) Close() error { return nil }
+
+// write replaces the file with the tasks in memory.
+func (s *JSONStore) write() error {
+	data, err := json.MarshalIndent(jsonFile{LastID: s.lastID, Tasks: sortedTasks(s.tasks)}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("writing task store: %w", err)
+	}
+	_, err = tmp.Write(append(data, '\n'))
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing task store: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package tasks
+
+import (
+	"database/sql"
+	"fmt"
+
+	_ "modernc.org/sqlite" // pure Go, so the trackers still build with CGO_ENABLED=0
+)
+
+// SQLiteStore keeps tasks in a table of an SQLite database file, and the highest ID
+// ever saved in a row of its meta table.
+type SQLiteStore struct {
+	db *sql.DB
+}
+
+// OpenSQLiteStore opens the database at path, creating it and its tables when needed.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening task store: %w", err)
+	}
+	// SQLite allows one writer at a time; a single connection makes callers wait their
+	// turn instead of failing with SQLITE_BUSY.
+	db.SetMaxOpenConns(1)
+
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS tasks (
+		id          INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		completed   INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS meta (
+		key   TEXT PRIMARY KEY,
+		value INTEGER NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening task store %s: %w", path, err)
+	}
+	return &SQLiteStore{db: db}, nil
+}
+
+// Load returns every stored task, ordered by ID.
+func (s *SQLiteStore) Load() ([]Task, error) {
+	rows, err := s.db.Query(`SELECT id, description, completed FROM tasks ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("loading tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var out []Task
+	for rows.Next() {
+		var task Task
+		if err := rows.Scan(&task.ID, &task.Description, &task.Completed); err != nil {
+			return nil, fmt.Errorf("loading tasks: %w", err)
+		}
+		out = append(out, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("loading tasks: %w", err)
+	}
+	return out, nil
+}
+
+// Save stores the task, replacing the one with the same ID if there is one.
+func (s *SQLiteStore) Save(task Task) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("saving task %d: %w", task.ID, err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO tasks (id, description, completed) VALUES (?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET description = excluded.description, completed = excluded.completed`,
+		task.ID, task.Description, task.Completed)
+	if err == nil {
+		_, err = tx.Exec(`INSERT INTO meta (key, value) VALUES ('last_id', ?)
+			ON CONFLICT(key) DO UPDATE SET value = max(value, excluded.value)`, task.ID)
+	}
+	if err == nil {
+		err = tx.Commit()
+	}
+	if err != nil {
+		return fmt.Errorf("saving task %d: %w", task.ID, err)
+	}
+	return nil
+}
+
+// Delete removes the task with the ID.
+func (s *SQLiteStore) Delete(id int) error {
+	if _, err := s.db.Exec(`DELETE FROM tasks WHERE id = ?`, id); err != nil {
+		return fmt.Errorf("deleting task %d: %w", id, err)
+	}
+	return nil
+}
+
+// LastID returns the highest ID ever saved.
+func (s *SQLiteStore) LastID() (int, error) {
+	var id int
+	err := s.db.QueryRow(`SELECT coalesce((SELECT value FROM meta WHERE key = 'last_id'), 0)`).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("reading the last task ID: %w", err)
+	}
+	return id, nil
+}
+
+// Close closes the database.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
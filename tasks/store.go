@@ -0,0 +1,110 @@
+// Package tasks holds the task type shared by the task trackers and the stores that
+// keep their tasks across restarts.
+package tasks
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Task represents a single task in a tracker.
+type Task struct {
+	ID          int    `json:"id"`
+	Description string `json:"description"`
+	Completed   bool   `json:"completed"`
+}
+
+// TaskStore keeps the tasks of a tracker. Stores are safe for concurrent use.
+type TaskStore interface {
+	// Load returns every stored task, ordered by ID.
+	Load() ([]Task, error)
+	// Save stores the task, replacing the one with the same ID if there is one.
+	Save(task Task) error
+	// Delete removes the task with the ID. Deleting a task that is not stored is not an error.
+	Delete(id int) error
+	// LastID returns the highest ID ever saved, even if that task has since been deleted,
+	// so that a tracker can go on numbering tasks without reusing an ID.
+	LastID() (int, error)
+	// Close releases the store. It must not be used afterwards.
+	Close() error
+}
+
+// Open opens the store described by spec: "memory", "json:PATH" or "sqlite:PATH".
+func Open(spec string) (TaskStore, error) {
+	kind, path, _ := strings.Cut(spec, ":")
+	switch {
+	case kind == "memory" && path == "":
+		return NewMemoryStore(), nil
+	case kind == "json" && path != "":
+		return OpenJSONStore(path)
+	case kind == "sqlite" && path != "":
+		return OpenSQLiteStore(path)
+	}
+	return nil, fmt.Errorf("unknown task store %q, use memory, json:PATH or sqlite:PATH", spec)
+}
+
+// MemoryStore keeps tasks in memory only, so they are lost when the program exits.
+type MemoryStore struct {
+	mu     sync.Mutex
+	tasks  map[int]Task
+	lastID int
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tasks: map[int]Task{}}
+}
+
+// Load returns every stored task, ordered by ID.
+func (s *MemoryStore) Load() ([]Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return sortedTasks(s.tasks), nil
+}
+
+// Save stores the task, replacing the one with the same ID if there is one.
+func (s *MemoryStore) Save(task Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks[task.ID] = task
+	s.lastID = max(s.lastID, task.ID)
+	return nil
+}
+
+// Delete removes the task with the ID.
+func (s *MemoryStore) Delete(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tasks, id)
+	return nil
+}
+
+// LastID returns the highest ID ever saved.
+func (s *MemoryStore) LastID() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastID, nil
+}
+
+// Close does nothing; a MemoryStore holds no resources.
+func (s *MemoryStore) Close() error { return nil }
+
+func sortedTasks(tasks map[int]Task) []Task {
+	out := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		out = append(out, task)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// MaxID returns the highest ID among the tasks, or zero when there are none.
+func MaxID(tasks []Task) int {
+	highest := 0
+	for _, task := range tasks {
+		highest = max(highest, task.ID)
+	}
+	return highest
+}
@@ -0,0 +1,94 @@
+package tasks
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStores(t *testing.T) {
+	dir := t.TempDir()
+	memory := NewMemoryStore()
+	tests := []struct {
+		name string
+		open func() (TaskStore, error) // reopens the same store, as a restart would
+	}{
+		{"Memory", func() (TaskStore, error) { return memory, nil }},
+		{"JSON", func() (TaskStore, error) { return OpenJSONStore(filepath.Join(dir, "tasks.json")) }},
+		{"SQLite", func() (TaskStore, error) { return OpenSQLiteStore(filepath.Join(dir, "tasks.db")) }},
+	}
+
+	for _, tt := range tests {
+		s, err := tt.open()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got, err := s.Load(); err != nil || len(got) != 0 {
+			t.Errorf("%s: expected an empty store, got %v, %v", tt.name, got, err)
+		}
+		for _, task := range []Task{{3, "Clean", false}, {1, "Read a book", false}, {2, "Play", false}, {1, "Read a book", true}} {
+			if err := s.Save(task); err != nil {
+				t.Fatalf("%s: unexpected error: %v", tt.name, err)
+			}
+		}
+		if err := s.Delete(3); err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if err := s.Delete(99); err != nil {
+			t.Errorf("%s: deleting a missing task: %v", tt.name, err)
+		}
+		if err := s.Close(); err != nil {
+			t.Errorf("%s: unexpected error closing: %v", tt.name, err)
+		}
+
+		s, err = tt.open()
+		if err != nil {
+			t.Fatalf("%s: unexpected error reopening: %v", tt.name, err)
+		}
+		want := []Task{{1, "Read a book", true}, {2, "Play", false}}
+		if got, err := s.Load(); err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %v after reopening, got %v, %v", tt.name, want, got, err)
+		}
+		if got, err := s.LastID(); err != nil || got != 3 {
+			t.Errorf("%s: expected the deleted task 3 to remain the last ID, got %d, %v", tt.name, got, err)
+		}
+		s.Close()
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 2 {
+		t.Errorf("Expected only tasks.json and tasks.db to be left, got %v", entries)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	for _, spec := range []string{"memory", "json:" + filepath.Join(dir, "a.json"), "sqlite:" + filepath.Join(dir, "a.db")} {
+		s, err := Open(spec)
+		if err != nil {
+			t.Errorf("Open(%q): unexpected error: %v", spec, err)
+			continue
+		}
+		s.Close()
+	}
+	for _, spec := range []string{"", "json", "json:", "memory:x", "bolt:a.db"} {
+		if _, err := Open(spec); err == nil {
+			t.Errorf("Open(%q): expected an error", spec)
+		}
+	}
+
+	bad := filepath.Join(dir, "bad.json")
+	os.WriteFile(bad, []byte("{not json"), 0o644)
+	if _, err := OpenJSONStore(bad); err == nil {
+		t.Errorf("Expected an error opening a corrupt JSON store")
+	}
+}
+
+func TestMaxID(t *testing.T) {
+	if got := MaxID([]Task{{ID: 4}, {ID: 9}, {ID: 2}}); got != 9 {
+		t.Errorf("MaxID = %d, want 9", got)
+	}
+	if got := MaxID(nil); got != 0 {
+		t.Errorf("MaxID(nil) = %d, want 0", got)
+	}
+}